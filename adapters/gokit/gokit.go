@@ -0,0 +1,90 @@
+// Package gokit adapts a dslogger Logger to the go-kit log.Logger interface
+// (github.com/go-kit/log and github.com/go-kit/kit/log), Log(keyvals ...any) error:
+//
+//	var kitLogger log.Logger = gokit.New(logger)
+//	level.Info(kitLogger).Log("msg", "listening", "addr", addr)
+//
+// The package does not import go-kit, the Logger implements the interface by its method set.
+// The "level" value set by go-kit's level package selects the dslogger level (Info when
+// absent), the "msg" value becomes the message and the other pairs become fields.
+package gokit
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/K4rian/dslogger"
+)
+
+// Keys read from the key/value pairs.
+const (
+	LevelKey   = "level"
+	MessageKey = "msg"
+)
+
+// Logger implements the go-kit log.Logger interface on top of a dslogger Logger.
+type Logger struct {
+	logger *dslogger.Logger
+}
+
+// New returns a go-kit logger writing to logger.
+func New(logger *dslogger.Logger) *Logger {
+	return &Logger{logger: logger}
+}
+
+// Log logs keyvals as one entry. The caller is the first frame outside go-kit's packages,
+// so loggers wrapped with log.With and level.Info report the call to their Log method.
+func (k *Logger) Log(keyvals ...any) error {
+	if len(keyvals)%2 != 0 {
+		keyvals = append(keyvals, nil)
+	}
+	lvl := zapcore.InfoLevel
+	msg := ""
+	fields := make([]any, 0, len(keyvals))
+	for i := 0; i < len(keyvals); i += 2 {
+		switch fmt.Sprint(keyvals[i]) {
+		case LevelKey:
+			if l, ok := parseLevel(keyvals[i+1]); ok {
+				lvl = l
+				continue
+			}
+		case MessageKey:
+			msg = fmt.Sprint(keyvals[i+1])
+			continue
+		}
+		fields = append(fields, keyvals[i], keyvals[i+1])
+	}
+	k.logger.LogDepth(callerDepth(), lvl, msg, fields...)
+	return nil
+}
+
+// callerDepth returns the LogDepth depth, from Log, of the first frame outside go-kit.
+func callerDepth() int {
+	var pcs [16]uintptr
+	n := runtime.Callers(3, pcs[:]) // skip runtime.Callers, callerDepth and Log
+	for i, pc := range pcs[:n] {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		if !strings.HasPrefix(frame.Function, "github.com/go-kit/") {
+			return i + 1
+		}
+	}
+	return 1
+}
+
+// parseLevel maps a go-kit level value (level.Value or a string) to a dslogger level.
+func parseLevel(v any) (zapcore.Level, bool) {
+	switch strings.ToLower(fmt.Sprint(v)) {
+	case "debug":
+		return zapcore.DebugLevel, true
+	case "info":
+		return zapcore.InfoLevel, true
+	case "warn", "warning":
+		return zapcore.WarnLevel, true
+	case "error":
+		return zapcore.ErrorLevel, true
+	}
+	return 0, false
+}
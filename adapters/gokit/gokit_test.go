@@ -0,0 +1,85 @@
+package gokit
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/K4rian/dslogger"
+)
+
+// kitLogger is a copy of github.com/go-kit/log.Logger.
+type kitLogger interface {
+	Log(keyvals ...any) error
+}
+
+var _ kitLogger = (*Logger)(nil)
+
+// kitLevel mimics go-kit's level.Value, a fmt.Stringer.
+type kitLevel string
+
+func (l kitLevel) String() string { return string(l) }
+
+// kitContext mimics go-kit's log.With, prepending bound pairs.
+type kitContext struct {
+	next    kitLogger
+	keyvals []any
+}
+
+func (c kitContext) Log(keyvals ...any) error {
+	return c.next.Log(append(c.keyvals, keyvals...)...)
+}
+
+// TestKitLogger checks level and message extraction, fields and the caller.
+func TestKitLogger(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := dslogger.NewDefaultConfig()
+	cfg.ConsoleWriter = &buf
+	cfg.ConsoleFormat = dslogger.LogFormatJSON
+	cfg.ConsoleCaller = dslogger.CallerConfig{Format: dslogger.CallerShort}
+	logger, err := dslogger.NewConsoleLogger("info", &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kit kitLogger = New(logger)
+
+	tests := []struct {
+		keyvals []any
+		level   string
+		want    map[string]any
+	}{
+		{[]any{"msg", "listening", "addr", ":80"}, "INFO", map[string]any{"message": "listening", "addr": ":80"}},
+		{[]any{"level", kitLevel("warn"), "msg", "slow", "ms", 950}, "WARN", map[string]any{"message": "slow", "ms": float64(950)}},
+		{[]any{"level", "error", "err", "boom"}, "ERROR", map[string]any{"message": "", "err": "boom"}},
+		{[]any{"k", "v", "odd"}, "INFO", map[string]any{"k": "v", "odd": nil}},
+	}
+	for _, tt := range tests {
+		buf.Reset()
+		_, _, line, _ := runtime.Caller(0)
+		_ = kit.Log(tt.keyvals...)
+		var entry map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+		}
+		if lvl, _ := entry["level"].(string); strings.TrimSpace(lvl) != tt.level {
+			t.Errorf("%v: level = %q, want %s", tt.keyvals, lvl, tt.level)
+		}
+		for k, v := range tt.want {
+			if got, ok := entry[k]; !ok || got != v {
+				t.Errorf("%v: %s = %v, want %v", tt.keyvals, k, entry[k], v)
+			}
+		}
+		if caller, _ := entry["caller"].(string); !strings.HasSuffix(caller, "gokit_test.go:"+strconv.Itoa(line+1)) {
+			t.Errorf("caller = %q, want gokit_test.go:%d", caller, line+1)
+		}
+	}
+
+	buf.Reset()
+	_ = kitContext{next: kit, keyvals: []any{"component", "api"}}.Log("msg", "bound")
+	if !strings.Contains(buf.String(), `"component":"api"`) {
+		t.Errorf("bound pairs lost: %q", buf.String())
+	}
+}
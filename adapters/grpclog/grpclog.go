@@ -0,0 +1,134 @@
+// Package grpclog adapts a dslogger Logger to gRPC's grpclog.LoggerV2 and
+// grpclog.DepthLoggerV2 interfaces:
+//
+//	grpclog.SetLoggerV2(grpcadapter.New(logger.WithService("grpc"), 0))
+//
+// The package does not import gRPC, the Logger implements both interfaces by their method
+// sets. Info, Warning and Error map to the dslogger levels of the same name. Fatal logs at
+// Error level with a [FATAL] tag, like Logger.Fatal, then exits.
+package grpclog
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/K4rian/dslogger"
+)
+
+// osExit is os.Exit by default.
+// Overridden in tests to avoid killing the test process.
+var osExit = os.Exit
+
+// Logger implements grpclog.LoggerV2 and grpclog.DepthLoggerV2 on top of a dslogger Logger.
+type Logger struct {
+	logger    *dslogger.Logger
+	verbosity int
+}
+
+// New returns a gRPC logger writing to logger. V(l) reports true for l up to verbosity,
+// the equivalent of GRPC_GO_LOG_VERBOSITY_LEVEL.
+func New(logger *dslogger.Logger, verbosity int) *Logger {
+	return &Logger{logger: logger, verbosity: verbosity}
+}
+
+// Info logs args at Info level, formatted as by fmt.Sprint.
+func (g *Logger) Info(args ...any) {
+	g.logger.LogDepth(1, zapcore.InfoLevel, fmt.Sprint(args...))
+}
+
+// Infoln logs args at Info level, formatted as by fmt.Sprintln.
+func (g *Logger) Infoln(args ...any) {
+	g.logger.LogDepth(1, zapcore.InfoLevel, sprintln(args))
+}
+
+// Infof logs at Info level, formatted as by fmt.Sprintf.
+func (g *Logger) Infof(format string, args ...any) {
+	g.logger.LogDepth(1, zapcore.InfoLevel, fmt.Sprintf(format, args...))
+}
+
+// InfoDepth logs args at Info level with the caller depth frames above its own caller.
+func (g *Logger) InfoDepth(depth int, args ...any) {
+	g.logger.LogDepth(depth+1, zapcore.InfoLevel, fmt.Sprint(args...))
+}
+
+// Warning logs args at Warn level, formatted as by fmt.Sprint.
+func (g *Logger) Warning(args ...any) {
+	g.logger.LogDepth(1, zapcore.WarnLevel, fmt.Sprint(args...))
+}
+
+// Warningln logs args at Warn level, formatted as by fmt.Sprintln.
+func (g *Logger) Warningln(args ...any) {
+	g.logger.LogDepth(1, zapcore.WarnLevel, sprintln(args))
+}
+
+// Warningf logs at Warn level, formatted as by fmt.Sprintf.
+func (g *Logger) Warningf(format string, args ...any) {
+	g.logger.LogDepth(1, zapcore.WarnLevel, fmt.Sprintf(format, args...))
+}
+
+// WarningDepth logs args at Warn level with the caller depth frames above its own caller.
+func (g *Logger) WarningDepth(depth int, args ...any) {
+	g.logger.LogDepth(depth+1, zapcore.WarnLevel, fmt.Sprint(args...))
+}
+
+// Error logs args at Error level, formatted as by fmt.Sprint.
+func (g *Logger) Error(args ...any) {
+	g.logger.LogDepth(1, zapcore.ErrorLevel, fmt.Sprint(args...))
+}
+
+// Errorln logs args at Error level, formatted as by fmt.Sprintln.
+func (g *Logger) Errorln(args ...any) {
+	g.logger.LogDepth(1, zapcore.ErrorLevel, sprintln(args))
+}
+
+// Errorf logs at Error level, formatted as by fmt.Sprintf.
+func (g *Logger) Errorf(format string, args ...any) {
+	g.logger.LogDepth(1, zapcore.ErrorLevel, fmt.Sprintf(format, args...))
+}
+
+// ErrorDepth logs args at Error level with the caller depth frames above its own caller.
+func (g *Logger) ErrorDepth(depth int, args ...any) {
+	g.logger.LogDepth(depth+1, zapcore.ErrorLevel, fmt.Sprint(args...))
+}
+
+// Fatal logs args with a [FATAL] tag, formatted as by fmt.Sprint, then exits.
+func (g *Logger) Fatal(args ...any) {
+	g.fatal(2, fmt.Sprint(args...))
+}
+
+// Fatalln logs args with a [FATAL] tag, formatted as by fmt.Sprintln, then exits.
+func (g *Logger) Fatalln(args ...any) {
+	g.fatal(2, sprintln(args))
+}
+
+// Fatalf logs with a [FATAL] tag, formatted as by fmt.Sprintf, then exits.
+func (g *Logger) Fatalf(format string, args ...any) {
+	g.fatal(2, fmt.Sprintf(format, args...))
+}
+
+// FatalDepth logs args with a [FATAL] tag and the caller depth frames above its own
+// caller, then exits.
+func (g *Logger) FatalDepth(depth int, args ...any) {
+	g.fatal(depth+2, fmt.Sprint(args...))
+}
+
+// V reports whether verbosity level l is enabled.
+func (g *Logger) V(l int) bool {
+	return l <= g.verbosity
+}
+
+// fatal logs msg at Error level with a [FATAL] tag, flushes the logger and exits.
+// depth counts from the caller of fatal.
+func (g *Logger) fatal(depth int, msg string) {
+	g.logger.LogDepth(depth, zapcore.ErrorLevel, "[FATAL] "+msg)
+	_ = g.logger.Sync()
+	osExit(1)
+}
+
+// sprintln formats args as fmt.Sprintln does, without the trailing newline.
+func sprintln(args []any) string {
+	return strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+}
@@ -0,0 +1,113 @@
+package grpclog
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/K4rian/dslogger"
+)
+
+// loggerV2 is a copy of google.golang.org/grpc/grpclog.LoggerV2.
+type loggerV2 interface {
+	Info(args ...any)
+	Infoln(args ...any)
+	Infof(format string, args ...any)
+	Warning(args ...any)
+	Warningln(args ...any)
+	Warningf(format string, args ...any)
+	Error(args ...any)
+	Errorln(args ...any)
+	Errorf(format string, args ...any)
+	Fatal(args ...any)
+	Fatalln(args ...any)
+	Fatalf(format string, args ...any)
+	V(l int) bool
+}
+
+// depthLoggerV2 is a copy of google.golang.org/grpc/grpclog.DepthLoggerV2.
+type depthLoggerV2 interface {
+	loggerV2
+	InfoDepth(depth int, args ...any)
+	WarningDepth(depth int, args ...any)
+	ErrorDepth(depth int, args ...any)
+	FatalDepth(depth int, args ...any)
+}
+
+var _ depthLoggerV2 = (*Logger)(nil)
+
+// newTestLogger returns a JSON console dslogger writing to buf.
+func newTestLogger(t *testing.T, buf *bytes.Buffer) *dslogger.Logger {
+	t.Helper()
+	cfg := dslogger.NewDefaultConfig()
+	cfg.ConsoleWriter = buf
+	cfg.ConsoleFormat = dslogger.LogFormatJSON
+	cfg.ConsoleCaller = dslogger.CallerConfig{Format: dslogger.CallerShort}
+	logger, err := dslogger.NewConsoleLogger("info", &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+// callLine returns the line number of its call, the logging call sharing that line.
+func callLine() string {
+	_, _, line, _ := runtime.Caller(1)
+	return strconv.Itoa(line)
+}
+
+// TestLoggerV2 checks the level, message and caller of every method.
+func TestLoggerV2(t *testing.T) {
+	var buf bytes.Buffer
+	var g depthLoggerV2 = New(newTestLogger(t, &buf), 2)
+
+	exited := 0
+	osExit = func(int) { exited++ }
+	t.Cleanup(func() { osExit = os.Exit })
+
+	tests := []struct {
+		level, msg string
+		log        func() string
+	}{
+		{"INFO", "a1", func() string { l := callLine(); g.Info("a", 1); return l }},
+		{"INFO", "a 1", func() string { l := callLine(); g.Infoln("a", 1); return l }},
+		{"INFO", "a=1", func() string { l := callLine(); g.Infof("a=%d", 1); return l }},
+		{"WARN", "w", func() string { l := callLine(); g.Warning("w"); return l }},
+		{"WARN", "w x", func() string { l := callLine(); g.Warningln("w", "x"); return l }},
+		{"WARN", "w=1", func() string { l := callLine(); g.Warningf("w=%d", 1); return l }},
+		{"ERROR", "e", func() string { l := callLine(); g.Error("e"); return l }},
+		{"ERROR", "e x", func() string { l := callLine(); g.Errorln("e", "x"); return l }},
+		{"ERROR", "e=1", func() string { l := callLine(); g.Errorf("e=%d", 1); return l }},
+		{"ERROR", "[FATAL] f", func() string { l := callLine(); g.Fatal("f"); return l }},
+		{"ERROR", "[FATAL] f x", func() string { l := callLine(); g.Fatalln("f", "x"); return l }},
+		{"ERROR", "[FATAL] f=1", func() string { l := callLine(); g.Fatalf("f=%d", 1); return l }},
+		{"INFO", "d", func() string { l := callLine(); g.InfoDepth(0, "d"); return l }},
+		{"WARN", "d", func() string { l := callLine(); g.WarningDepth(0, "d"); return l }},
+		{"ERROR", "d", func() string { l := callLine(); g.ErrorDepth(0, "d"); return l }},
+		{"ERROR", "[FATAL] d", func() string { l := callLine(); g.FatalDepth(0, "d"); return l }},
+	}
+	for _, tt := range tests {
+		buf.Reset()
+		line := tt.log()
+		var entry map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+		}
+		if lvl, _ := entry["level"].(string); strings.TrimSpace(lvl) != tt.level || entry["message"] != tt.msg {
+			t.Errorf("got %v, want %s %q", entry, tt.level, tt.msg)
+		}
+		if caller, _ := entry["caller"].(string); !strings.HasSuffix(caller, "grpclog_test.go:"+line) {
+			t.Errorf("%s: caller = %q, want grpclog_test.go:%s", tt.msg, caller, line)
+		}
+	}
+	if exited != 4 {
+		t.Errorf("exits = %d, want 4", exited)
+	}
+	if !g.V(2) || g.V(3) {
+		t.Error("V does not follow the verbosity")
+	}
+}
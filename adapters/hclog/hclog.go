@@ -0,0 +1,262 @@
+// Package hclog adapts a dslogger Logger to the github.com/hashicorp/go-hclog Logger
+// interface used by HashiCorp libraries (Raft, Vault, Consul, go-plugin).
+//
+// Trace maps to Debug, the other hclog levels to the dslogger level of the same name.
+// Names given with Named are joined with "." and become the service name, arguments given
+// with With become fields. SetLevel sets a level of the adapter, shared with the loggers
+// derived from it, filtering entries on top of the underlying Logger's level, which it
+// leaves unchanged.
+package hclog
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"runtime"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/hashicorp/go-hclog"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/K4rian/dslogger"
+)
+
+// Logger implements hclog.Logger on top of a dslogger Logger.
+type Logger struct {
+	logger  *dslogger.Logger
+	name    string
+	implied []any
+	level   *atomic.Int32 // hclog.Level set by SetLevel, levelUnset until then
+}
+
+// levelUnset is the adapter level until SetLevel is called, leaving the filtering to the
+// underlying Logger.
+const levelUnset = -1
+
+var _ hclog.Logger = (*Logger)(nil)
+
+// New returns an hclog.Logger writing to logger.
+func New(logger *dslogger.Logger) hclog.Logger {
+	level := new(atomic.Int32)
+	level.Store(levelUnset)
+	return &Logger{logger: logger, name: logger.ServiceName(), level: level}
+}
+
+// enabled reports whether the adapter level lets entries at level through. hclog.Off
+// drops everything.
+func (h *Logger) enabled(level hclog.Level) bool {
+	switch set := hclog.Level(h.level.Load()); set {
+	case levelUnset:
+		return true
+	case hclog.Off:
+		return false
+	case hclog.NoLevel:
+		return level == hclog.NoLevel || level >= hclog.Info
+	default:
+		return level == hclog.NoLevel || level >= set
+	}
+}
+
+// logDepth logs msg at level when the adapter level allows it.
+func (h *Logger) logDepth(depth int, level hclog.Level, msg string, args ...any) {
+	if h.enabled(level) {
+		h.logger.LogDepth(depth+1, toZap(level), msg, args...)
+	}
+}
+
+// isEnabled reports whether entries at level pass both the adapter and the underlying
+// Logger levels.
+func (h *Logger) isEnabled(level hclog.Level) bool {
+	return h.enabled(level) && h.logger.Level().Enabled(toZap(level))
+}
+
+// Log logs msg at level, hclog.NoLevel being Info and hclog.Off dropping the entry.
+func (h *Logger) Log(level hclog.Level, msg string, args ...any) {
+	if level == hclog.Off {
+		return
+	}
+	h.logDepth(1, level, msg, args...)
+}
+
+// Trace logs msg at Debug level.
+func (h *Logger) Trace(msg string, args ...any) {
+	h.logDepth(1, hclog.Trace, msg, args...)
+}
+
+// Debug logs msg at Debug level.
+func (h *Logger) Debug(msg string, args ...any) {
+	h.logDepth(1, hclog.Debug, msg, args...)
+}
+
+// Info logs msg at Info level.
+func (h *Logger) Info(msg string, args ...any) {
+	h.logDepth(1, hclog.Info, msg, args...)
+}
+
+// Warn logs msg at Warn level.
+func (h *Logger) Warn(msg string, args ...any) {
+	h.logDepth(1, hclog.Warn, msg, args...)
+}
+
+// Error logs msg at Error level.
+func (h *Logger) Error(msg string, args ...any) {
+	h.logDepth(1, hclog.Error, msg, args...)
+}
+
+// IsTrace reports whether Trace entries are logged.
+func (h *Logger) IsTrace() bool { return h.isEnabled(hclog.Trace) }
+
+// IsDebug reports whether Debug entries are logged.
+func (h *Logger) IsDebug() bool { return h.isEnabled(hclog.Debug) }
+
+// IsInfo reports whether Info entries are logged.
+func (h *Logger) IsInfo() bool { return h.isEnabled(hclog.Info) }
+
+// IsWarn reports whether Warn entries are logged.
+func (h *Logger) IsWarn() bool { return h.isEnabled(hclog.Warn) }
+
+// IsError reports whether Error entries are logged.
+func (h *Logger) IsError() bool { return h.isEnabled(hclog.Error) }
+
+// ImpliedArgs returns the arguments added with With.
+func (h *Logger) ImpliedArgs() []any {
+	return slices.Clone(h.implied)
+}
+
+// With returns a logger adding args as fields to every entry.
+func (h *Logger) With(args ...any) hclog.Logger {
+	return &Logger{
+		logger:  h.logger.WithFields(args...),
+		name:    h.name,
+		implied: append(slices.Clip(h.implied), args...),
+		level:   h.level,
+	}
+}
+
+// Name returns the logger's name.
+func (h *Logger) Name() string {
+	return h.name
+}
+
+// Named returns a logger whose name is name appended to the current one.
+func (h *Logger) Named(name string) hclog.Logger {
+	if h.name != "" {
+		name = h.name + "." + name
+	}
+	return h.ResetNamed(name)
+}
+
+// ResetNamed returns a logger named name.
+func (h *Logger) ResetNamed(name string) hclog.Logger {
+	return &Logger{logger: h.logger.WithService(name), name: name, implied: h.implied, level: h.level}
+}
+
+// SetLevel sets the level of the adapter and the loggers derived from it, hclog.NoLevel
+// being Info and hclog.Off dropping every entry. Entries must pass the underlying
+// Logger's level too, which is left unchanged.
+func (h *Logger) SetLevel(level hclog.Level) {
+	h.level.Store(int32(level))
+}
+
+// GetLevel returns the level set with SetLevel, or the level of the underlying Logger
+// when none was set.
+func (h *Logger) GetLevel() hclog.Level {
+	if set := hclog.Level(h.level.Load()); set != levelUnset {
+		return set
+	}
+	switch lvl := h.logger.Level(); {
+	case lvl <= zapcore.DebugLevel:
+		return hclog.Debug
+	case lvl == zapcore.InfoLevel:
+		return hclog.Info
+	case lvl == zapcore.WarnLevel:
+		return hclog.Warn
+	case lvl == zapcore.ErrorLevel:
+		return hclog.Error
+	}
+	return hclog.Off
+}
+
+// StandardLogger returns a *log.Logger writing to StandardWriter(opts).
+func (h *Logger) StandardLogger(opts *hclog.StandardLoggerOptions) *log.Logger {
+	return log.New(h.StandardWriter(opts), "", 0)
+}
+
+// StandardWriter returns an io.Writer logging each write as one entry, reported from the
+// log call. The level is opts.ForceLevel when set, else the [TRACE], [DEBUG], [INFO],
+// [WARN] or [ERROR] prefix of the line with opts.InferLevels, else Info.
+func (h *Logger) StandardWriter(opts *hclog.StandardLoggerOptions) io.Writer {
+	if opts == nil {
+		opts = &hclog.StandardLoggerOptions{}
+	}
+	return &stdWriter{logger: h, opts: *opts}
+}
+
+// stdWriter is the io.Writer returned by StandardWriter.
+type stdWriter struct {
+	logger *Logger
+	opts   hclog.StandardLoggerOptions
+}
+
+// levelPrefixes are the line prefixes recognised with InferLevels.
+var levelPrefixes = []struct {
+	prefix string
+	level  hclog.Level
+}{
+	{"[TRACE]", hclog.Trace},
+	{"[DEBUG]", hclog.Debug},
+	{"[INFO]", hclog.Info},
+	{"[WARN]", hclog.Warn},
+	{"[ERROR]", hclog.Error},
+	{"[ERR]", hclog.Error},
+}
+
+// Write implements io.Writer.
+func (w *stdWriter) Write(p []byte) (int, error) {
+	msg := string(bytes.TrimRight(p, " \t\n"))
+	level := hclog.Info
+	switch {
+	case w.opts.ForceLevel != hclog.NoLevel:
+		level = w.opts.ForceLevel
+	case w.opts.InferLevels:
+		for _, lp := range levelPrefixes {
+			if rest, ok := strings.CutPrefix(msg, lp.prefix); ok {
+				level, msg = lp.level, strings.TrimSpace(rest)
+				break
+			}
+		}
+	}
+	if level != hclog.Off {
+		w.logger.logDepth(stdCallerDepth(), level, msg)
+	}
+	return len(p), nil
+}
+
+// stdCallerDepth returns the LogDepth depth, from Write, of the first frame outside the
+// log package.
+func stdCallerDepth() int {
+	var pcs [8]uintptr
+	n := runtime.Callers(3, pcs[:]) // skip runtime.Callers, stdCallerDepth and Write
+	for i, pc := range pcs[:n] {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		if !strings.HasPrefix(frame.Function, "log.") {
+			return i + 1
+		}
+	}
+	return 1
+}
+
+// toZap maps an hclog level to a dslogger level.
+func toZap(level hclog.Level) zapcore.Level {
+	switch level {
+	case hclog.Trace, hclog.Debug:
+		return zapcore.DebugLevel
+	case hclog.Warn:
+		return zapcore.WarnLevel
+	case hclog.Error:
+		return zapcore.ErrorLevel
+	}
+	return zapcore.InfoLevel
+}
@@ -0,0 +1,170 @@
+package hclog
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/K4rian/dslogger"
+)
+
+// newTestLogger returns a JSON console dslogger writing to buf.
+func newTestLogger(t *testing.T, buf *bytes.Buffer, level string) *dslogger.Logger {
+	t.Helper()
+	cfg := dslogger.NewDefaultConfig()
+	cfg.ConsoleWriter = buf
+	cfg.ConsoleFormat = dslogger.LogFormatJSON
+	cfg.ConsoleCaller = dslogger.CallerConfig{Format: dslogger.CallerShort}
+	logger, err := dslogger.NewConsoleLogger(level, &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+// decode decodes the JSON entry in buf and resets it.
+func decode(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	defer buf.Reset()
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	return entry
+}
+
+// callLine returns the line number of its call, the logging call sharing that line.
+func callLine() string {
+	_, _, line, _ := runtime.Caller(1)
+	return strconv.Itoa(line)
+}
+
+// nextLine returns the line number following its call.
+func nextLine() string {
+	_, _, line, _ := runtime.Caller(1)
+	return strconv.Itoa(line + 1)
+}
+
+// TestHclogLevelsAndCaller checks the level mapping and the caller of each method.
+func TestHclogLevelsAndCaller(t *testing.T) {
+	var buf bytes.Buffer
+	h := New(newTestLogger(t, &buf, "debug"))
+
+	tests := []struct {
+		level string
+		log   func() string
+	}{
+		{"DEBUG", func() string { l := callLine(); h.Trace("m", "k", 1); return l }},
+		{"DEBUG", func() string { l := callLine(); h.Debug("m", "k", 1); return l }},
+		{"INFO", func() string { l := callLine(); h.Info("m", "k", 1); return l }},
+		{"WARN", func() string { l := callLine(); h.Warn("m", "k", 1); return l }},
+		{"ERROR", func() string { l := callLine(); h.Error("m", "k", 1); return l }},
+		{"WARN", func() string { l := callLine(); h.Log(hclog.Warn, "m", "k", 1); return l }},
+		{"INFO", func() string { l := callLine(); h.Log(hclog.NoLevel, "m", "k", 1); return l }},
+	}
+	for _, tt := range tests {
+		line := tt.log()
+		entry := decode(t, &buf)
+		if lvl, _ := entry["level"].(string); strings.TrimSpace(lvl) != tt.level || entry["k"] != float64(1) {
+			t.Errorf("got %v, want level %s", entry, tt.level)
+		}
+		if caller, _ := entry["caller"].(string); !strings.HasSuffix(caller, "hclog_test.go:"+line) {
+			t.Errorf("caller = %q, want hclog_test.go:%s", caller, line)
+		}
+	}
+
+	h.Log(hclog.Off, "dropped")
+	if buf.Len() != 0 {
+		t.Errorf("entry at hclog.Off: %q", buf.String())
+	}
+}
+
+// TestHclogNamesAndArgs checks Named, ResetNamed, With and ImpliedArgs.
+func TestHclogNamesAndArgs(t *testing.T) {
+	var buf bytes.Buffer
+	h := New(newTestLogger(t, &buf, "info")).Named("raft").With("node", "n1").Named("fsm")
+
+	if h.Name() != "raft.fsm" || len(h.ImpliedArgs()) != 2 {
+		t.Errorf("name = %q, implied = %v", h.Name(), h.ImpliedArgs())
+	}
+	h.Info("applied")
+	entry := decode(t, &buf)
+	if entry["service"] != "raft.fsm" || entry["node"] != "n1" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+
+	h.ResetNamed("snap").Info("taken")
+	if entry := decode(t, &buf); entry["service"] != "snap" || entry["node"] != "n1" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+}
+
+// TestHclogSetLevel checks SetLevel, GetLevel and the Is* methods, and that SetLevel
+// leaves the underlying Logger alone.
+func TestHclogSetLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(t, &buf, "debug")
+	h := New(logger)
+	named := h.Named("raft")
+
+	if h.GetLevel() != hclog.Debug || !h.IsDebug() || !h.IsTrace() {
+		t.Errorf("level = %v", h.GetLevel())
+	}
+	h.SetLevel(hclog.Warn)
+	named.Info("dropped")
+	if h.GetLevel() != hclog.Warn || named.IsInfo() || !named.IsWarn() || buf.Len() != 0 {
+		t.Errorf("level after SetLevel(Warn) = %v, output %q", h.GetLevel(), buf.String())
+	}
+	if logger.Level() != zapcore.DebugLevel {
+		t.Errorf("underlying level = %v", logger.Level())
+	}
+	h.StandardLogger(&hclog.StandardLoggerOptions{ForceLevel: hclog.Warn}).Print("kept")
+	if entry := decode(t, &buf); entry["message"] != "kept" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+
+	h.SetLevel(hclog.Off)
+	h.Error("dropped")
+	h.Log(hclog.NoLevel, "dropped")
+	if h.GetLevel() != hclog.Off || h.IsError() || buf.Len() != 0 {
+		t.Errorf("level after SetLevel(Off) = %v, output %q", h.GetLevel(), buf.String())
+	}
+
+	h.SetLevel(hclog.Trace)
+	if !h.IsTrace() || New(newTestLogger(t, &buf, "info")).IsTrace() {
+		t.Error("Trace enabled above the underlying level")
+	}
+}
+
+// TestHclogStandardLogger checks forced and inferred levels and the caller.
+func TestHclogStandardLogger(t *testing.T) {
+	var buf bytes.Buffer
+	h := New(newTestLogger(t, &buf, "debug"))
+
+	std := h.StandardLogger(&hclog.StandardLoggerOptions{InferLevels: true})
+	line := nextLine()
+	std.Printf("[WARN] disk %d%% full", 91)
+	entry := decode(t, &buf)
+	if lvl, _ := entry["level"].(string); strings.TrimSpace(lvl) != "WARN" || entry["message"] != "disk 91% full" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+	if caller, _ := entry["caller"].(string); !strings.HasSuffix(caller, "hclog_test.go:"+line) {
+		t.Errorf("caller = %q, want hclog_test.go:%s", caller, line)
+	}
+
+	h.StandardLogger(&hclog.StandardLoggerOptions{ForceLevel: hclog.Error}).Print("[DEBUG] forced")
+	if entry := decode(t, &buf); strings.TrimSpace(entry["level"].(string)) != "ERROR" || entry["message"] != "[DEBUG] forced" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+
+	h.StandardLogger(nil).Print("plain")
+	if entry := decode(t, &buf); strings.TrimSpace(entry["level"].(string)) != "INFO" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+}
@@ -0,0 +1,22 @@
+module github.com/K4rian/dslogger/adapters/logr
+
+go 1.26.2
+
+replace github.com/K4rian/dslogger => ../..
+
+require (
+	github.com/K4rian/dslogger v0.0.0
+	github.com/go-logr/logr v1.4.3
+	go.uber.org/zap v1.27.1
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	go.opentelemetry.io/otel v1.43.0 // indirect
+	go.opentelemetry.io/otel/trace v1.43.0 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	golang.org/x/sys v0.48.0 // indirect
+	golang.org/x/term v0.46.0 // indirect
+	golang.org/x/text v0.42.0 // indirect
+	gopkg.in/natefinch/lumberjack.v2 v2.2.1 // indirect
+)
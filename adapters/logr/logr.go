@@ -0,0 +1,78 @@
+// Package logr adapts a dslogger Logger to the github.com/go-logr/logr API used by
+// Kubernetes controller-runtime and client-go.
+//
+// V-levels map to dslogger levels: V(0) is Info and any higher verbosity is Debug.
+// Names given with WithName are joined with "/" and become the service name, values
+// given with WithValues become fields.
+package logr
+
+import (
+	"github.com/go-logr/logr"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/K4rian/dslogger"
+)
+
+// sink implements logr.LogSink and logr.CallDepthLogSink on top of a dslogger Logger.
+type sink struct {
+	logger *dslogger.Logger
+	name   string
+	depth  int // frames between the user's call and the sink, set by Init and WithCallDepth
+}
+
+// New returns a logr.Logger writing to logger.
+func New(logger *dslogger.Logger) logr.Logger {
+	return logr.New(NewLogSink(logger))
+}
+
+// NewLogSink returns a logr.LogSink writing to logger.
+func NewLogSink(logger *dslogger.Logger) logr.LogSink {
+	return &sink{logger: logger, name: logger.ServiceName()}
+}
+
+// Init implements logr.LogSink.
+func (s *sink) Init(info logr.RuntimeInfo) {
+	s.depth = info.CallDepth
+}
+
+// Enabled implements logr.LogSink.
+func (s *sink) Enabled(level int) bool {
+	return s.logger.Level().Enabled(vLevel(level))
+}
+
+// Info implements logr.LogSink.
+func (s *sink) Info(level int, msg string, keysAndValues ...any) {
+	s.logger.LogDepth(s.depth+1, vLevel(level), msg, keysAndValues...)
+}
+
+// Error implements logr.LogSink. The error is logged under the "error" key.
+func (s *sink) Error(err error, msg string, keysAndValues ...any) {
+	fields := append([]any{"error", err}, keysAndValues...)
+	s.logger.LogDepth(s.depth+1, zapcore.ErrorLevel, msg, fields...)
+}
+
+// WithValues implements logr.LogSink.
+func (s *sink) WithValues(keysAndValues ...any) logr.LogSink {
+	return &sink{logger: s.logger.WithFields(keysAndValues...), name: s.name, depth: s.depth}
+}
+
+// WithName implements logr.LogSink.
+func (s *sink) WithName(name string) logr.LogSink {
+	if s.name != "" {
+		name = s.name + "/" + name
+	}
+	return &sink{logger: s.logger.WithService(name), name: name, depth: s.depth}
+}
+
+// WithCallDepth implements logr.CallDepthLogSink.
+func (s *sink) WithCallDepth(depth int) logr.LogSink {
+	return &sink{logger: s.logger, name: s.name, depth: s.depth + depth}
+}
+
+// vLevel maps a logr V-level to a dslogger level.
+func vLevel(level int) zapcore.Level {
+	if level > 0 {
+		return zapcore.DebugLevel
+	}
+	return zapcore.InfoLevel
+}
@@ -0,0 +1,99 @@
+package logr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/K4rian/dslogger"
+)
+
+var (
+	_ logr.LogSink          = (*sink)(nil)
+	_ logr.CallDepthLogSink = (*sink)(nil)
+)
+
+// newTestLogger returns a JSON console dslogger writing to buf.
+func newTestLogger(t *testing.T, buf *bytes.Buffer, level string) *dslogger.Logger {
+	t.Helper()
+	cfg := dslogger.NewDefaultConfig()
+	cfg.ConsoleWriter = buf
+	cfg.ConsoleFormat = dslogger.LogFormatJSON
+	cfg.ConsoleCaller = dslogger.CallerConfig{Format: dslogger.CallerShort}
+	logger, err := dslogger.NewConsoleLogger(level, &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+// lastEntry decodes the last JSON entry in buf.
+func lastEntry(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	var entry map[string]any
+	if err := json.Unmarshal(lines[len(lines)-1], &entry); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	return entry
+}
+
+// nextLine returns the line number following the call.
+func nextLine() string {
+	_, _, line, _ := runtime.Caller(1)
+	return strconv.Itoa(line + 1)
+}
+
+// TestLogr checks levels, names, values, errors and the caller through logr.Logger.
+func TestLogr(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(newTestLogger(t, &buf, "info")).WithName("ctrl").WithName("pod").WithValues("ns", "default")
+
+	line := nextLine()
+	log.Info("reconciled", "pod", "web-1")
+	entry := lastEntry(t, &buf)
+	if entry["service"] != "ctrl/pod" || entry["ns"] != "default" || entry["pod"] != "web-1" ||
+		strings.TrimSpace(entry["level"].(string)) != "INFO" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+	if caller, _ := entry["caller"].(string); !strings.HasSuffix(caller, "logr_test.go:"+line) {
+		t.Errorf("caller = %q, want logr_test.go:%s", caller, line)
+	}
+
+	buf.Reset()
+	log.V(1).Info("verbose")
+	if buf.Len() != 0 || log.V(1).Enabled() {
+		t.Errorf("V(1) enabled at info level: %q", buf.String())
+	}
+
+	log.Error(errors.New("boom"), "failed")
+	entry = lastEntry(t, &buf)
+	if entry["error"] != "boom" || strings.TrimSpace(entry["level"].(string)) != "ERROR" {
+		t.Errorf("unexpected error entry: %v", entry)
+	}
+}
+
+// TestLogrCallDepth checks helpers marked with WithCallDepth and the V-level mapping.
+func TestLogrCallDepth(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(newTestLogger(t, &buf, "debug"))
+
+	helper := func(msg string) {
+		log.WithCallDepth(1).V(2).Info(msg)
+	}
+	line := nextLine()
+	helper("from helper")
+	entry := lastEntry(t, &buf)
+	if caller, _ := entry["caller"].(string); !strings.HasSuffix(caller, "logr_test.go:"+line) {
+		t.Errorf("caller = %q, want logr_test.go:%s", caller, line)
+	}
+	if strings.TrimSpace(entry["level"].(string)) != "DEBUG" {
+		t.Errorf("V(2) level = %v, want DEBUG", entry["level"])
+	}
+}
@@ -0,0 +1,110 @@
+package dslogger
+
+import (
+	"path"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// CallerFormat selects how the caller location is rendered.
+type CallerFormat string
+
+// Supported caller formats.
+const (
+	CallerShort  CallerFormat = "short"  // pkg/file.go:line, zap's ShortCallerEncoder
+	CallerFull   CallerFormat = "full"   // /abs/path/to/pkg/file.go:line
+	CallerModule CallerFormat = "module" // path relative to the main module root, import path for dependencies
+)
+
+// CallerConfig selects the caller information reported by one output.
+type CallerConfig struct {
+	// Format enables the caller (under the EncoderConfig's CallerKey, "caller" when unset)
+	// with the given rendering. Empty leaves the EncoderConfig's caller settings untouched.
+	Format CallerFormat
+
+	// Function also reports the calling function (under FunctionKey, "function" when unset).
+	// The text formats print it package-qualified (pkg.(*T).Method), JSON in full.
+	Function bool
+}
+
+// apply sets up encCfg for cc.
+func (cc CallerConfig) apply(encCfg *zapcore.EncoderConfig) {
+	if cc.Format != "" {
+		if encCfg.CallerKey == "" {
+			encCfg.CallerKey = "caller"
+		}
+		encCfg.EncodeCaller = cc.Format.encoder()
+	}
+	if cc.Function && encCfg.FunctionKey == "" {
+		encCfg.FunctionKey = "function"
+	}
+}
+
+// encoder returns the zapcore.CallerEncoder for f, CallerShort for unknown values.
+func (f CallerFormat) encoder() zapcore.CallerEncoder {
+	switch f {
+	case CallerFull:
+		return zapcore.FullCallerEncoder
+	case CallerModule:
+		return ModuleCallerEncoder
+	default:
+		return zapcore.ShortCallerEncoder
+	}
+}
+
+// ModuleCallerEncoder renders the caller as a path relative to the main module root,
+// such as internal/api/handler.go:42. Callers from other modules are qualified by their
+// import path (github.com/org/lib/file.go:10).
+func ModuleCallerEncoder(caller zapcore.EntryCaller, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(moduleCallerPath(caller))
+}
+
+func moduleCallerPath(caller zapcore.EntryCaller) string {
+	if !caller.Defined {
+		return "undefined"
+	}
+	pkg := funcPackage(caller.Function)
+	if pkg == "" {
+		return caller.TrimmedPath()
+	}
+	mainPkg, module := mainModule()
+	if pkg == "main" && mainPkg != "" {
+		pkg = mainPkg
+	}
+
+	dir := pkg
+	if module != "" && (pkg == module || strings.HasPrefix(pkg, module+"/")) {
+		dir = strings.TrimPrefix(strings.TrimPrefix(pkg, module), "/")
+	}
+	return path.Join(dir, path.Base(caller.File)) + ":" + strconv.Itoa(caller.Line)
+}
+
+// mainModule returns the main package and main module paths of the running binary.
+var mainModule = sync.OnceValues(func() (string, string) {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", ""
+	}
+	return bi.Path, bi.Main.Path
+})
+
+// funcPackage returns the import path of a runtime function name such as
+// github.com/org/mod/pkg.(*T).Method, or "" when it cannot tell.
+func funcPackage(fn string) string {
+	slash := strings.LastIndexByte(fn, '/')
+	dot := strings.IndexByte(fn[slash+1:], '.')
+	if dot < 0 {
+		return ""
+	}
+	return fn[:slash+1+dot]
+}
+
+// shortFuncName trims the import path directories of a function name, keeping the
+// package-qualified form pkg.(*T).Method.
+func shortFuncName(fn string) string {
+	return fn[strings.LastIndexByte(fn, '/')+1:]
+}
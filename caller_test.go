@@ -0,0 +1,223 @@
+package dslogger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// callerEntryPoints logs once through every public entry point.
+var callerEntryPoints = []struct {
+	name string
+	log  func(l *Logger)
+}{
+	{"Info", func(l *Logger) { l.Info("msg") }},
+	{"Infof", func(l *Logger) { l.Infof("msg %d", 1) }},
+	{"Warnf", func(l *Logger) { l.Warnf("msg %d", 1) }},
+	{"Err", func(l *Logger) { l.Err(errors.New("boom"), "msg") }},
+	{"InfoContext", func(l *Logger) { l.InfoContext(context.Background(), "msg") }},
+	{"WithFields", func(l *Logger) { l.WithFields("k", "v").Error("msg") }},
+	{"WithService", func(l *Logger) { l.WithService("svc").Warn("msg") }},
+}
+
+// TestCallerFormatsAcrossEntryPoints checks each caller format and the function name
+// on the file output for every entry point.
+func TestCallerFormatsAcrossEntryPoints(t *testing.T) {
+	wd, _ := os.Getwd()
+	formats := []struct {
+		format CallerFormat
+		want   func(caller string) bool
+	}{
+		{CallerShort, func(c string) bool { return strings.Count(c, "/") == 1 && strings.Contains(c, "/caller_test.go:") }},
+		{CallerFull, func(c string) bool { return strings.HasPrefix(c, wd+"/caller_test.go:") }},
+		{CallerModule, func(c string) bool { return strings.HasPrefix(c, "caller_test.go:") }},
+	}
+	for _, f := range formats {
+		for _, ep := range callerEntryPoints {
+			t.Run(string(f.format)+"/"+ep.name, func(t *testing.T) {
+				withTempLogFile(t, func(path string, cfg *Config) {
+					cfg.LogFileFormat = LogFormatJSON
+					cfg.FileConfig = DefaultJSONEncoderConfig
+					cfg.FileCaller = CallerConfig{Format: f.format, Function: true}
+					cfg.ConsoleWriter = &bytes.Buffer{}
+					logger, err := NewLogger("info", cfg)
+					if err != nil {
+						t.Fatal(err)
+					}
+					ep.log(logger)
+					_ = logger.Close()
+
+					data, _ := os.ReadFile(path)
+					var entry map[string]any
+					if err := json.Unmarshal(data, &entry); err != nil {
+						t.Fatalf("invalid JSON: %v\n%s", err, data)
+					}
+					caller, _ := entry["caller"].(string)
+					if !strings.HasSuffix(strings.Split(caller, ":")[0], "caller_test.go") || !f.want(caller) {
+						t.Errorf("caller = %q", caller)
+					}
+					if fn, _ := entry["function"].(string); !strings.HasPrefix(fn, dsloggerPkg+".init.func") {
+						t.Errorf("function = %q, want the entry point closure", fn)
+					}
+				})
+			})
+		}
+	}
+}
+
+// TestCallerConsoleAndFileSeparately verifies the console and file caller settings are independent.
+func TestCallerConsoleAndFileSeparately(t *testing.T) {
+	withTempLogFile(t, func(path string, cfg *Config) {
+		var buf bytes.Buffer
+		cfg.ConsoleWriter = &buf
+		cfg.NoColor = true
+		cfg.ConsoleCaller = CallerConfig{Format: CallerModule, Function: true}
+		cfg.FileCaller = CallerConfig{Format: CallerFull}
+		cfg.LogFileFormat = LogFormatText
+		logger, err := NewLogger("info", cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger.Info("separate")
+		_ = logger.Close()
+
+		fields := strings.Split(buf.String(), " | ")
+		if len(fields) != 5 || !strings.HasPrefix(fields[2], "caller_test.go:") ||
+			fields[3] != "dslogger.TestCallerConsoleAndFileSeparately.func1" {
+			t.Errorf("unexpected console line: %q", buf.String())
+		}
+
+		data, _ := os.ReadFile(path)
+		wd, _ := os.Getwd()
+		if !strings.Contains(string(data), " | "+wd+"/caller_test.go:") || strings.Contains(string(data), "func1") {
+			t.Errorf("unexpected file line: %q", data)
+		}
+	})
+}
+
+// TestModuleCallerEncoder checks module-relative paths for this module and for dependencies.
+func TestModuleCallerEncoder(t *testing.T) {
+	tests := []struct {
+		caller zapcore.EntryCaller
+		want   string
+	}{
+		{zapcore.EntryCaller{Defined: true, Function: dsloggerPkg + ".(*Logger).Info", File: "/src/dslogger/logger.go", Line: 12},
+			"logger.go:12"},
+		{zapcore.EntryCaller{Defined: true, Function: dsloggerPkg + "/adapters/logr.sink.Info", File: "/src/dslogger/adapters/logr/logr.go", Line: 3},
+			"adapters/logr/logr.go:3"},
+		{zapcore.EntryCaller{Defined: true, Function: "github.com/org/lib/sub.F", File: "/go/pkg/mod/github.com/org/lib@v1.0.0/sub/x.go", Line: 7},
+			"github.com/org/lib/sub/x.go:7"},
+		{zapcore.EntryCaller{Defined: true, File: "/src/a/b/c.go", Line: 1}, "b/c.go:1"},
+	}
+	for _, tt := range tests {
+		if got := moduleCallerPath(tt.caller); got != tt.want {
+			t.Errorf("moduleCallerPath(%s) = %q, want %q", tt.caller.Function, got, tt.want)
+		}
+	}
+}
+
+// logHelper logs through LogDepth on behalf of its caller.
+func logHelper(l *Logger, msg string) {
+	l.LogDepth(1, zapcore.WarnLevel, msg, "k", "v")
+}
+
+// TestLogDepth checks the depth is counted from the caller of LogDepth and that levels
+// above Error are clamped.
+func TestLogDepth(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slogTestLogger(t, &buf)
+
+	line := thisLine()
+	logHelper(logger, "helped")
+	entry := decodeEntry(t, &buf)
+	if caller, _ := entry["caller"].(string); !strings.HasSuffix(caller, "/caller_test.go:"+line) || entry["k"] != "v" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+
+	buf.Reset()
+	line = thisLine()
+	logger.LogDepth(0, zapcore.FatalLevel, "not fatal")
+	entry = decodeEntry(t, &buf)
+	if caller, _ := entry["caller"].(string); !strings.HasSuffix(caller, "/caller_test.go:"+line) ||
+		strings.TrimSpace(entry["level"].(string)) != "ERROR" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+}
+
+// infoHelper logs on behalf of its caller, for WithCallerSkip(1).
+func infoHelper(l *Logger, msg string) {
+	l.Info(msg)
+}
+
+// TestCallerSkipWithSink checks WithCallerSkip reaches the console and the sinks whatever
+// the order of the options rebuilding them.
+func TestCallerSkipWithSink(t *testing.T) {
+	var consBuf, sinkBuf bytes.Buffer
+	cfg := NewDefaultConfig()
+	cfg.ConsoleWriter = &consBuf
+	cfg.ConsoleFormat = LogFormatJSON
+	cfg.ConsoleCaller = CallerConfig{Format: CallerShort}
+	encCfg := zapcore.EncoderConfig{MessageKey: "message", CallerKey: "caller", EncodeCaller: zapcore.ShortCallerEncoder}
+	sink := zapcore.NewCore(zapcore.NewJSONEncoder(encCfg), zapcore.AddSync(&sinkBuf), zapcore.DebugLevel)
+	logger, err := NewConsoleLogger("info", &cfg, WithCallerSkip(1), WithSink(sink), WithServiceName("svc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	line := thisLine()
+	infoHelper(logger, "helped")
+	for name, buf := range map[string]*bytes.Buffer{"console": &consBuf, "sink": &sinkBuf} {
+		entry := decodeEntry(t, buf)
+		if caller, _ := entry["caller"].(string); !strings.HasSuffix(caller, "/caller_test.go:"+line) {
+			t.Errorf("%s caller = %q, want caller_test.go:%s", name, caller, line)
+		}
+	}
+}
+
+// logDepthHelper logs through LogDepth on behalf of its caller, for WithCallerSkip(1).
+func logDepthHelper(l *Logger, msg string) {
+	l.LogDepth(0, zapcore.InfoLevel, msg)
+}
+
+// TestCallerSkipDerived checks loggers derived from one built WithCallerSkip, and LogDepth,
+// keep the skip on the console and the sinks.
+func TestCallerSkipDerived(t *testing.T) {
+	var consBuf, sinkBuf bytes.Buffer
+	cfg := NewDefaultConfig()
+	cfg.ConsoleWriter = &consBuf
+	cfg.ConsoleFormat = LogFormatJSON
+	cfg.ConsoleCaller = CallerConfig{Format: CallerShort}
+	encCfg := zapcore.EncoderConfig{MessageKey: "message", CallerKey: "caller", EncodeCaller: zapcore.ShortCallerEncoder}
+	sink := zapcore.NewCore(zapcore.NewJSONEncoder(encCfg), zapcore.AddSync(&sinkBuf), zapcore.DebugLevel)
+	logger, err := NewConsoleLogger("info", &cfg, WithCallerSkip(1), WithSink(sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), RequestIDKey, "r-1")
+
+	derived := map[string]*Logger{
+		"WithService": logger.WithService("svc"),
+		"WithFields":  logger.WithFields("k", "v"),
+		"WithContext": logger.WithContext(ctx),
+		"nested":      logger.WithService("svc").WithFields("k", "v"),
+	}
+	for name, l := range derived {
+		for _, helper := range []func(*Logger, string){infoHelper, logDepthHelper} {
+			line := thisLine()
+			helper(l, "helped")
+			for out, buf := range map[string]*bytes.Buffer{"console": &consBuf, "sink": &sinkBuf} {
+				entry := decodeEntry(t, buf)
+				if caller, _ := entry["caller"].(string); !strings.HasSuffix(caller, "/caller_test.go:"+line) {
+					t.Errorf("%s %s caller = %q, want caller_test.go:%s", name, out, caller, line)
+				}
+				buf.Reset()
+			}
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"reflect"
 
 	"go.uber.org/zap/zapcore"
+	"golang.org/x/term"
 )
 
 // LogFormat defines the output format for log files.
@@ -39,13 +40,22 @@ type Config struct {
 	LevelFormats          map[zapcore.Level]LevelFormat
 
 	// NoColor disables ANSI color codes on the console encoder.
-	// If unset (false), the library auto-detects non-TTY stdout and disables color automatically.
+	// If unset (false), the library disables color when NO_COLOR is set, CLICOLOR=0,
+	// TERM=dumb, or the console writer is not a terminal (unless FORCE_COLOR or
+	// CLICOLOR_FORCE say otherwise).
 	NoColor bool
 
 	// ForceColor overrides the auto-detection and forces ANSI color codes on the console
-	// encoder, even when stdout is not a terminal. Takes precedence over NoColor when both are true.
+	// encoder, even when the console writer is not a terminal. Takes precedence over
+	// NoColor when both are true.
 	ForceColor bool
 
+	// Theme colors the console elements besides the level: timestamp, caller, service
+	// name, field keys, field values by type and the message per level. When nil the
+	// text format only colors the level and LogFormatPretty uses DarkTheme.
+	// Themes are never applied to file output or when color is disabled.
+	Theme *Theme
+
 	// FileMode sets the permission bits used when pre-creating the log file. Lumberjack
 	// creates rotated backups with its own default (0600). This field only controls the
 	// primary log file. A zero value means no pre-creation
@@ -58,14 +68,77 @@ type Config struct {
 
 	// ConsoleFormat controls the console output format. Defaults to LogFormatText
 	// (the human-readable dslogger format).
-	// Set to LogFormatJSON for structured JSON on stdout
+	// Set to LogFormatJSON for structured JSON on stdout, or LogFormatPretty for the
+	// multi-line development format (one aligned field per line, nested values expanded)
 	ConsoleFormat LogFormat
+
+	// CallerWidth, ServiceWidth and MessageWidth fix the width, in terminal cells (East
+	// Asian wide characters taking two, combining marks none), of the caller,
+	// service name (decorators included) and message columns of the text formats so
+	// that lines stay aligned. Shorter values are padded with spaces. Longer callers and
+	// service names lose their head to an ellipsis, longer messages their tail.
+	// Zero leaves the column unchanged.
+	CallerWidth  int
+	ServiceWidth int
+	MessageWidth int
+
+	// NestedFormat controls how the text formats render nested objects and arrays on
+	// one line. Defaults to NestedBraces.
+	NestedFormat NestedFormat
+
+	// StacktraceLevel attaches a stack trace to every entry at or above this level
+	// ("warn", "error", ...). Empty disables it, ForceStack still works per call.
+	// Text output prints one frame per line below the entry, JSON adds a "stacktrace" field.
+	StacktraceLevel string
+
+	// StacktraceMaxDepth limits the number of frames of captured and error stacks,
+	// after dslogger and zap frames are trimmed. Zero means 32.
+	StacktraceMaxDepth int
+
+	// ConsoleCaller and FileCaller select the caller location format and function-name
+	// capture of each output, see CallerConfig. The zero value keeps ConsoleConfig and
+	// FileConfig as they are.
+	ConsoleCaller CallerConfig
+	FileCaller    CallerConfig
+
+	// FieldMerge selects how fields sharing a key are merged across WithCustomFields,
+	// WithFields, WithContext and the logging call, in every output. Empty means
+	// MergeKeepAll, every field is written as zap does.
+	FieldMerge FieldMerge
+
+	// SpanEventLevel records every entry at or above this level as an event of the
+	// OpenTelemetry span of its context, with the fields as attributes, when the span is
+	// recording. It applies to loggers from WithContext, the Context methods and the slog
+	// bridge. Empty disables it.
+	SpanEventLevel string
+
+	// SpanStatusLevel sets the status of that span to Error, described by the message,
+	// for every entry at or above this level. Empty disables it.
+	SpanStatusLevel string
+
+	// Parsed from StacktraceLevel, SpanEventLevel and SpanStatusLevel by the constructors.
+	stacktraceEnabled bool
+	stacktraceLevel   zapcore.Level
+	spanEventEnabled  bool
+	spanEventLevel    zapcore.Level
+	spanStatusEnabled bool
+	spanStatusLevel   zapcore.Level
 }
 
-// Supported log file formats.
+// NestedFormat selects the single-line rendering of nested objects and arrays.
+type NestedFormat string
+
+// Supported nested formats. Object keys keep their insertion order in both.
 const (
-	LogFormatText LogFormat = "text"
-	LogFormatJSON LogFormat = "json"
+	NestedBraces NestedFormat = "braces" // {a=1, b={c=2}} and [x, y]
+	NestedJSON   NestedFormat = "json"   // {"a":1,"b":{"c":2}} and ["x","y"]
+)
+
+// Supported log formats. LogFormatPretty is a console-only development format.
+const (
+	LogFormatText   LogFormat = "text"
+	LogFormatJSON   LogFormat = "json"
+	LogFormatPretty LogFormat = "pretty"
 )
 
 // cloneConfig returns a deep copy of in. The returned *Config shares no slice or
@@ -77,6 +150,7 @@ func cloneConfig(in *Config) *Config {
 		return &c
 	}
 	c := *in
+	c.Theme = in.Theme.clone()
 	if in.LevelFormats != nil {
 		c.LevelFormats = make(map[zapcore.Level]LevelFormat, len(in.LevelFormats))
 		for k, v := range in.LevelFormats {
@@ -129,14 +203,22 @@ func applyDefaults(cfg *Config) {
 		cfg.LevelFormats = d.LevelFormats // d is locally owned
 	}
 
-	// Auto-detect non-TTY stdout and disable color unless explicitly configured
-	// ForceColor takes precedence: if set, ensure NoColor is false regardless of TTY
+	// Auto-detect color unless explicitly configured. ForceColor takes precedence,
+	// then NoColor, then the NO_COLOR/FORCE_COLOR/CLICOLOR environment, then whether
+	// the console writer (not necessarily os.Stdout) is a color-capable terminal.
 	if cfg.ForceColor {
 		cfg.NoColor = false
-	} else if !cfg.NoColor && !stdoutIsTerminal() {
-		cfg.NoColor = true
+	} else if !cfg.NoColor {
+		if disabled, ok := colorDisabledByEnv(); ok {
+			cfg.NoColor = disabled
+		} else {
+			cfg.NoColor = !writerSupportsColor(cfg.consoleOut())
+		}
 	}
 
+	cfg.ConsoleCaller.apply(&cfg.ConsoleConfig)
+	cfg.FileCaller.apply(&cfg.FileConfig)
+
 	cfg.ConsoleConfig.ConsoleSeparator = cfg.ConsoleSeparator
 	cfg.ConsoleConfig.EncodeLevel = FixedWidthCapitalColorLevelEncoder(cfg)
 
@@ -158,12 +240,21 @@ func (c *Config) consoleOut() io.Writer {
 	return os.Stdout
 }
 
-// stdoutIsTerminal reports whether os.Stdout is attached to a terminal (character device).
-// When stdout is redirected to a file or pipe, ANSI color codes should be suppressed.
-func stdoutIsTerminal() bool {
-	stat, err := os.Stdout.Stat()
-	if err != nil {
+// fdWriter is implemented by writers backed by a file descriptor, such as *os.File.
+type fdWriter interface {
+	Fd() uintptr
+}
+
+// writerSupportsColor reports whether w is a terminal able to render ANSI colors:
+// it must expose a file descriptor attached to a terminal, and TERM must not be "dumb".
+// Buffers, pipes, regular files and network connections never get color automatically.
+func writerSupportsColor(w io.Writer) bool {
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := w.(fdWriter)
+	if !ok {
 		return false
 	}
-	return (stat.Mode() & os.ModeCharDevice) != 0
+	return term.IsTerminal(int(f.Fd()))
 }
@@ -29,21 +29,31 @@ func NewSimpleLogger(level string) (*Logger, error) {
 	return newLogger(level, nil, true)
 }
 
-// buildConsoleZap creates a zap SugaredLogger for console output.
-// When ConsoleFormat is LogFormatJSON it uses zap's stock JSON encoder,
-// otherwise it uses the custom dsConsoleEncoder.
+// newConsoleEncoder returns the console encoder selected by cfg.ConsoleFormat:
+// zap's stock JSON encoder for LogFormatJSON, dsPrettyEncoder for LogFormatPretty
+// and the custom dsConsoleEncoder otherwise.
+func newConsoleEncoder(cfg *Config, serviceName string) zapcore.Encoder {
+	switch cfg.ConsoleFormat {
+	case LogFormatJSON:
+		return zapcore.NewJSONEncoder(cfg.ConsoleConfig)
+	case LogFormatPretty:
+		enc := newDSPrettyEncoder(cfg, cfg.ConsoleConfig, serviceName)
+		enc.theme = cfg.consoleTheme(DarkTheme())
+		return enc
+	default:
+		enc := newDSConsoleEncoder(cfg, cfg.ConsoleConfig, serviceName)
+		enc.theme = cfg.consoleTheme(nil)
+		return enc
+	}
+}
+
+// buildConsoleZap creates a zap SugaredLogger for console output, see newConsoleEncoder.
 // The writer is wrapped in zapcore.Lock so that concurrent writers
 // cannot produce torn/garbage output.
 func buildConsoleZap(cfg *Config, level zap.AtomicLevel, serviceName string) *zap.SugaredLogger {
-	var encoder zapcore.Encoder
-
-	if cfg.ConsoleFormat == LogFormatJSON {
-		encoder = zapcore.NewJSONEncoder(cfg.ConsoleConfig)
-	} else {
-		encoder = newDSConsoleEncoder(cfg, cfg.ConsoleConfig, serviceName)
-	}
+	encoder := newConsoleEncoder(cfg, serviceName)
 	writer := zapcore.Lock(zapcore.AddSync(cfg.consoleOut()))
-	core := zapcore.NewCore(encoder, writer, level)
+	core := cfg.wrapCore(zapcore.NewCore(encoder, writer, level))
 	return zap.New(core, zap.AddCaller(), zap.AddCallerSkip(dsloggerCallerSkip)).Sugar()
 }
 
@@ -58,7 +68,7 @@ func buildFileZap(cfg *Config, level zap.AtomicLevel, ljLogger *lumberjack.Logge
 	} else {
 		encoder = newDSConsoleEncoder(cfg, cfg.FileConfig, serviceName)
 	}
-	core := zapcore.NewCore(encoder, zapcore.AddSync(ljLogger), level)
+	core := cfg.wrapCore(zapcore.NewCore(encoder, zapcore.AddSync(ljLogger), level))
 	return zap.New(core, zap.AddCaller(), zap.AddCallerSkip(dsloggerCallerSkip)).Sugar()
 }
 
@@ -80,6 +90,31 @@ func newLogger(level string, config *Config, fileLogging bool, opts ...Option) (
 	}
 	atomicLvl := zap.NewAtomicLevelAt(parsedLevel)
 
+	if cfg.StacktraceLevel != "" {
+		if lvl, err := parseLogLevel(cfg.StacktraceLevel); err != nil {
+			fmt.Fprintf(os.Stderr, "dslogger: stacktrace %v; stack traces disabled\n", err)
+		} else {
+			cfg.stacktraceEnabled, cfg.stacktraceLevel = true, lvl
+		}
+	}
+	if cfg.SpanEventLevel != "" {
+		if lvl, err := parseLogLevel(cfg.SpanEventLevel); err != nil {
+			fmt.Fprintf(os.Stderr, "dslogger: span event %v; span events disabled\n", err)
+		} else {
+			cfg.spanEventEnabled, cfg.spanEventLevel = true, lvl
+		}
+	}
+	if cfg.SpanStatusLevel != "" {
+		if lvl, err := parseLogLevel(cfg.SpanStatusLevel); err != nil {
+			fmt.Fprintf(os.Stderr, "dslogger: span status %v; span status disabled\n", err)
+		} else {
+			cfg.spanStatusEnabled, cfg.spanStatusLevel = true, lvl
+		}
+	}
+	if _, err := parseFieldMerge(cfg.FieldMerge); err != nil {
+		fmt.Fprintf(os.Stderr, "dslogger: %v; falling back to %s\n", err, MergeKeepAll)
+	}
+
 	logger := &Logger{
 		config:      cfg,
 		level:       atomicLvl,
@@ -111,6 +146,7 @@ func newLogger(level string, config *Config, fileLogging bool, opts ...Option) (
 			return nil, err
 		}
 	}
+	logger.applyCallerSkip()
 	return logger, nil
 }
 
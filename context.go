@@ -0,0 +1,172 @@
+package dslogger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// ContextExtractor returns key-value pairs to log for a context, such as a tenant or user
+// ID stored by middleware. It must be safe for concurrent use and should return nil when
+// the context carries nothing of interest.
+type ContextExtractor func(ctx context.Context) []any
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   []ContextExtractor // global extractors, replaced on registration
+)
+
+// RegisterContextExtractor adds fn to the extractors consulted by every Logger's
+// WithContext and by the slog bridge. It is meant to be called during initialization.
+func RegisterContextExtractor(fn ContextExtractor) {
+	if fn == nil {
+		return
+	}
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	extractors = append(slices.Clip(extractors), fn)
+}
+
+// globalExtractors returns the registered global extractors.
+func globalExtractors() []ContextExtractor {
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+	return extractors
+}
+
+// WithContextExtractor adds fn to the extractors consulted by this logger and the loggers
+// derived from it, after the global ones.
+func WithContextExtractor(fn ContextExtractor) Option {
+	return func(l *Logger) error {
+		if fn == nil {
+			return errors.New("dslogger: WithContextExtractor requires a non-nil extractor")
+		}
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.extractors = append(slices.Clip(l.extractors), fn)
+		return nil
+	}
+}
+
+// ctxFieldsKey and ctxLoggerKey are the context keys of ContextWithFields and NewContext.
+const (
+	ctxFieldsKey ctxKey = "dslogger.fields"
+	ctxLoggerKey ctxKey = "dslogger.logger"
+)
+
+// ContextWithFields returns a copy of ctx carrying kv, added to the fields already stashed
+// by earlier calls. WithContext, the slog bridge and the loggers of NewContext log them.
+func ContextWithFields(ctx context.Context, kv ...any) context.Context {
+	if len(kv) == 0 {
+		return ctx
+	}
+	prev, _ := ctx.Value(ctxFieldsKey).([]any)
+	return context.WithValue(ctx, ctxFieldsKey, append(slices.Clip(prev), normalizeFields(slices.Clip(kv))...))
+}
+
+// NewContext returns a copy of ctx carrying l, retrieved with FromContext.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, ctxLoggerKey, l)
+}
+
+// FromContext returns the Logger stored in ctx by NewContext, or Default when ctx carries
+// none, with the fields WithContext extracts from ctx attached. A nil ctx yields Default.
+func FromContext(ctx context.Context) *Logger {
+	if ctx == nil {
+		return Default()
+	}
+	l, _ := ctx.Value(ctxLoggerKey).(*Logger)
+	if l == nil {
+		l = Default()
+	}
+	return l.WithContext(ctx)
+}
+
+var defaultLogger atomic.Pointer[Logger]
+
+// fallbackLogger is the Default logger until SetDefault is called, an Info level console
+// logger with the default configuration.
+var fallbackLogger = sync.OnceValue(func() *Logger {
+	l, err := NewSimpleConsoleLogger("info")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dslogger: default logger: %v; logging disabled\n", err)
+		cfg := NewDefaultConfig()
+		return &Logger{config: &cfg, level: zap.NewAtomicLevel()} // no outputs
+	}
+	return l
+})
+
+// Default returns the logger set by SetDefault, or an Info level console logger with
+// the default configuration.
+func Default() *Logger {
+	if l := defaultLogger.Load(); l != nil {
+		return l
+	}
+	return fallbackLogger()
+}
+
+// SetDefault makes l the logger returned by Default and used by FromContext for contexts
+// without a logger. A nil l restores the built-in default.
+func SetDefault(l *Logger) {
+	defaultLogger.Store(l)
+}
+
+// contextFields returns the fields WithContext extracts from ctx followed by fields.
+// fields is left for the log call to normalize, after it takes out ForceStack.
+func (l *Logger) contextFields(ctx context.Context, fields []any) []any {
+	if ctx == nil {
+		return fields
+	}
+	kv := l.appendContextFields(make([]any, 0, len(fields)+8), ctx)
+	return append(kv, fields...)
+}
+
+// appendContextFields appends to kv the key-value pairs WithContext attaches for ctx.
+func (l *Logger) appendContextFields(kv []any, ctx context.Context) []any {
+	// Typed dslogger keys
+	if requestID, _ := ctx.Value(RequestIDKey).(string); requestID != "" {
+		kv = append(kv, string(RequestIDKey), requestID)
+	}
+	// A span's trace ID takes precedence over the typed key, so trace_id is logged once
+	// whatever the FieldMerge policy
+	span := trace.SpanFromContext(ctx)
+	if traceID, _ := ctx.Value(TraceIDKey).(string); traceID != "" && !span.SpanContext().HasTraceID() {
+		kv = append(kv, string(TraceIDKey), traceID)
+	}
+
+	// OpenTelemetry span context
+	if span != nil {
+		sc := span.SpanContext()
+		if sc.HasTraceID() {
+			kv = append(kv, string(TraceIDKey), sc.TraceID().String())
+		}
+		// A remote span is the caller's, this service's span has not started
+		if sc.HasSpanID() && sc.IsRemote() {
+			kv = append(kv, string(ParentSpanIDKey), sc.SpanID().String())
+		} else if sc.HasSpanID() {
+			kv = append(kv, string(SpanIDKey), sc.SpanID().String())
+		}
+		if span := l.config.recordingSpan(span); span != nil {
+			kv = append(kv, spanMarker{span})
+		}
+	}
+
+	// Stashed fields, then the extractors
+	if fields, _ := ctx.Value(ctxFieldsKey).([]any); len(fields) > 0 {
+		kv = append(kv, fields...)
+	}
+	for _, fn := range globalExtractors() {
+		kv = append(kv, normalizeFields(slices.Clip(fn(ctx)))...)
+	}
+	for _, fn := range l.extractors {
+		kv = append(kv, normalizeFields(slices.Clip(fn(ctx)))...)
+	}
+	return kv
+}
@@ -0,0 +1,175 @@
+package dslogger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type tenantKey struct{}
+
+// withGlobalExtractor registers fn for the duration of the test.
+func withGlobalExtractor(t *testing.T, fn ContextExtractor) {
+	t.Helper()
+	extractorsMu.RLock()
+	prev := extractors
+	extractorsMu.RUnlock()
+	RegisterContextExtractor(fn)
+	t.Cleanup(func() {
+		extractorsMu.Lock()
+		extractors = prev
+		extractorsMu.Unlock()
+	})
+}
+
+// TestContextExtractors checks global and per-logger extractors, their order and the
+// normalization of odd results, through WithContext and the slog bridge.
+func TestContextExtractors(t *testing.T) {
+	withGlobalExtractor(t, func(ctx context.Context) []any {
+		if tenant, _ := ctx.Value(tenantKey{}).(string); tenant != "" {
+			return []any{"tenant", tenant}
+		}
+		return nil
+	})
+
+	var buf bytes.Buffer
+	logger := slogTestLogger(t, &buf)
+	if err := WithContextExtractor(func(ctx context.Context) []any { return []any{"odd"} })(logger); err != nil {
+		t.Fatal(err)
+	}
+	if err := WithContextExtractor(nil)(logger); err == nil {
+		t.Error("nil extractor accepted")
+	}
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	ctx = context.WithValue(ctx, RequestIDKey, "req-1")
+
+	logger.WithService("api").WithContext(ctx).Info("derived")
+	entry := decodeEntry(t, &buf)
+	if entry["tenant"] != "acme" || entry["request_id"] != "req-1" || entry["odd"] != "<missing>" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+
+	buf.Reset()
+	slog.New(NewSlogHandler(logger)).InfoContext(ctx, "bridged")
+	if entry := decodeEntry(t, &buf); entry["tenant"] != "acme" || entry["odd"] != "<missing>" {
+		t.Errorf("unexpected slog entry: %v", entry)
+	}
+
+	buf.Reset()
+	logger.WithContext(context.Background()).Info("empty")
+	if entry := decodeEntry(t, &buf); entry["tenant"] != nil {
+		t.Errorf("extractor result without tenant: %v", entry)
+	}
+}
+
+// TestContextWithFields checks fields accumulate across calls without aliasing.
+func TestContextWithFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slogTestLogger(t, &buf)
+
+	base := ContextWithFields(context.Background(), "user", "u-1")
+	a := ContextWithFields(base, "flag", "beta")
+	b := ContextWithFields(base, "flag", "stable", "dangling")
+
+	logger.WithContext(a).Info("a")
+	if entry := decodeEntry(t, &buf); entry["user"] != "u-1" || entry["flag"] != "beta" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+	buf.Reset()
+	logger.WithContext(b).Info("b")
+	if entry := decodeEntry(t, &buf); entry["flag"] != "stable" || entry["dangling"] != "<missing>" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+	if ContextWithFields(base) != base {
+		t.Error("ContextWithFields without fields should return ctx")
+	}
+}
+
+// TestNewContextFromContext checks the logger round trip, the context fields and the
+// fallback to Default.
+func TestNewContextFromContext(t *testing.T) {
+	var def bytes.Buffer
+	SetDefault(slogTestLogger(t, &def))
+	t.Cleanup(func() { SetDefault(nil) })
+	FromContext(ContextWithFields(context.Background(), "k", "v")).Info("default")
+	if entry := decodeEntry(t, &def); entry["k"] != "v" {
+		t.Errorf("unexpected default entry: %v", entry)
+	}
+	SetDefault(nil)
+	if Default() == nil || Default() != Default() {
+		t.Error("built-in default should be a single non-nil logger")
+	}
+	var nilCtx context.Context
+	if FromContext(nilCtx) != Default() {
+		t.Error("FromContext(nil) should return the default logger")
+	}
+
+	var buf bytes.Buffer
+	logger := slogTestLogger(t, &buf)
+	ctx := NewContext(context.Background(), logger.WithService("worker"))
+	ctx = ContextWithFields(ctx, "job", 7)
+
+	FromContext(ctx).Info("from context")
+	entry := decodeEntry(t, &buf)
+	if entry["service"] != "worker" || entry["job"] != float64(7) {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+}
+
+// TestContextMethods checks the per-call context methods log the context fields with
+// the right level and caller.
+func TestContextMethods(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slogTestLogger(t, &buf)
+	ctx := ContextWithFields(context.WithValue(context.Background(), RequestIDKey, "req-9"), "user", "u-2")
+
+	tests := []struct {
+		level string
+		log   func()
+	}{
+		{"DEBUG", func() { logger.DebugContext(ctx, "m", "k", 1) }},
+		{"INFO", func() { logger.InfoContext(ctx, "m", "k", 1) }},
+		{"WARN", func() { logger.WarnContext(ctx, "m", "k", 1) }},
+		{"ERROR", func() { logger.ErrorContext(ctx, "m", "k", 1) }},
+	}
+	for _, tt := range tests {
+		buf.Reset()
+		tt.log()
+		entry := decodeEntry(t, &buf)
+		if lvl, _ := entry["level"].(string); strings.TrimSpace(lvl) != tt.level ||
+			entry["request_id"] != "req-9" || entry["user"] != "u-2" || entry["k"] != float64(1) {
+			t.Errorf("unexpected entry: %v", entry)
+		}
+		if caller, _ := entry["caller"].(string); !strings.Contains(caller, "context_test.go:") {
+			t.Errorf("caller = %q", caller)
+		}
+	}
+
+	buf.Reset()
+	logger.InfoContext(nil, "no context", "k", "odd", "dangling")
+	if entry := decodeEntry(t, &buf); entry["dangling"] != "<missing>" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+}
+
+// TestContextMethodsAllocs verifies InfoContext costs less than a derived logger.
+func TestContextMethodsAllocs(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.ConsoleWriter = io.Discard
+	cfg.ConsoleFormat = LogFormatJSON
+	logger, err := NewConsoleLogger("info", &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), RequestIDKey, "req-9")
+
+	perCall := testing.AllocsPerRun(100, func() { logger.InfoContext(ctx, "msg", "k", 1) })
+	derived := testing.AllocsPerRun(100, func() { logger.WithContext(ctx).Info("msg", "k", 1) })
+	if perCall >= derived {
+		t.Errorf("allocs InfoContext = %v, WithContext().Info = %v", perCall, derived)
+	}
+}
@@ -24,32 +24,35 @@ var (
 	// The EncodeLevel field is intentionally left unset here, applyDefaults sets the fixed-width
 	// color level encoder bound to the target logger's Config.
 	DefaultConsoleEncoderConfig = zapcore.EncoderConfig{
-		TimeKey:      "timestamp",
-		LevelKey:     "level",
-		MessageKey:   "message",
-		EncodeTime:   zapcore.ISO8601TimeEncoder,
-		EncodeLevel:  zapcore.CapitalLevelEncoder,
-		EncodeCaller: zapcore.ShortCallerEncoder,
+		TimeKey:       "timestamp",
+		LevelKey:      "level",
+		MessageKey:    "message",
+		EncodeTime:    zapcore.ISO8601TimeEncoder,
+		EncodeLevel:   zapcore.CapitalLevelEncoder,
+		EncodeCaller:  zapcore.ShortCallerEncoder,
+		StacktraceKey: "stacktrace",
 	}
 
 	// DefaultTextEncoderConfig defines the default encoder configuration for plain text log output.
 	DefaultTextEncoderConfig = zapcore.EncoderConfig{
-		TimeKey:      "timestamp",
-		LevelKey:     "level",
-		MessageKey:   "message",
-		EncodeTime:   zapcore.ISO8601TimeEncoder,
-		EncodeLevel:  zapcore.CapitalLevelEncoder,
-		EncodeCaller: zapcore.ShortCallerEncoder,
+		TimeKey:       "timestamp",
+		LevelKey:      "level",
+		MessageKey:    "message",
+		EncodeTime:    zapcore.ISO8601TimeEncoder,
+		EncodeLevel:   zapcore.CapitalLevelEncoder,
+		EncodeCaller:  zapcore.ShortCallerEncoder,
+		StacktraceKey: "stacktrace",
 	}
 
 	// DefaultJSONEncoderConfig defines the default encoder configuration for JSON log output.
 	DefaultJSONEncoderConfig = zapcore.EncoderConfig{
-		TimeKey:      "timestamp",
-		LevelKey:     "level",
-		MessageKey:   "message",
-		EncodeTime:   zapcore.ISO8601TimeEncoder,
-		EncodeLevel:  zapcore.CapitalLevelEncoder,
-		EncodeCaller: zapcore.ShortCallerEncoder,
+		TimeKey:       "timestamp",
+		LevelKey:      "level",
+		MessageKey:    "message",
+		EncodeTime:    zapcore.ISO8601TimeEncoder,
+		EncodeLevel:   zapcore.CapitalLevelEncoder,
+		EncodeCaller:  zapcore.ShortCallerEncoder,
+		StacktraceKey: "stacktrace",
 	}
 )
 
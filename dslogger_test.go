@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
@@ -403,6 +404,168 @@ func TestGoldenTextFormatWithService(t *testing.T) {
 	})
 }
 
+// TestGoldenTextFormatColumns locks in fixed-width caller, service and message columns.
+func TestGoldenTextFormatColumns(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.CallerWidth = 14
+	cfg.ServiceWidth = 8
+	cfg.MessageWidth = 10
+	applyDefaults(&cfg)
+	cfg.ConsoleConfig.CallerKey = "caller"
+	cfg.ConsoleConfig.EncodeTime = func(t2 time.Time, enc zapcore.PrimitiveArrayEncoder) {
+		enc.AppendString("FIXED_TIME")
+	}
+	cfg.ConsoleConfig.EncodeLevel = FixedWidthCapitalLevelEncoder(&cfg)
+
+	encode := func(service, file, msg string, fields ...zapcore.Field) string {
+		enc := newDSConsoleEncoder(&cfg, cfg.ConsoleConfig, service)
+		buf, err := enc.EncodeEntry(zapcore.Entry{
+			Level:   zapcore.InfoLevel,
+			Message: msg,
+			Caller:  zapcore.EntryCaller{Defined: true, File: file, Line: 7},
+		}, fields)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+	port := zapcore.Field{Key: "port", Type: zapcore.Int64Type, Integer: 80}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"padded", encode("api", "/src/app/main.go", "up", port),
+			"FIXED_TIME | INFO  | app/main.go:7  | [api]    up         | port: 80\n"},
+		{"truncated", encode("scheduler", "/src/internal/handlers.go", "request completed", port),
+			"FIXED_TIME | INFO  | …handlers.go:7 | […duler] request c… | port: 80\n"},
+		{"multi-byte", encode("日本語サービス", "/src/app/main.go", "héllo wörld ünïcode", port),
+			"FIXED_TIME | INFO  | app/main.go:7  | […ビス]  héllo wör… | port: 80\n"},
+		{"combining marks", encode("api", "/src/app/main.go", "cafe\u0301 au lait", port),
+			"FIXED_TIME | INFO  | app/main.go:7  | [api]    cafe\u0301 au l… | port: 80\n"},
+		{"no service", encode("", "/src/app/main.go", "up", port),
+			"FIXED_TIME | INFO  | app/main.go:7  |          up         | port: 80\n"},
+		{"no trailing blanks", encode("api", "/src/app/main.go", "up"),
+			"FIXED_TIME | INFO  | app/main.go:7  | [api]    up\n"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s:\ngot:  %q\nwant: %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+// TestGoldenTextNestedValues locks in the single-line rendering of nested objects and
+// arrays in both NestedFormat modes.
+func TestGoldenTextNestedValues(t *testing.T) {
+	at := time.Date(2026, 1, 15, 10, 30, 0, 0, time.UTC)
+	req := zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		enc.AddString("path", "/a\nforged")
+		enc.AddInt("status", 200)
+		enc.AddDuration("took", 1500*time.Millisecond)
+		return enc.AddObject("client", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddTime("seen", at)
+			enc.AddBool("tls", true)
+			return nil
+		}))
+	})
+	hops := zapcore.ArrayMarshalerFunc(func(enc zapcore.ArrayEncoder) error {
+		enc.AppendString("edge")
+		enc.AppendTime(at)
+		return enc.AppendObject(zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddFloat64("w", 0.5)
+			return nil
+		}))
+	})
+
+	tests := []struct {
+		format NestedFormat
+		want   string
+	}{
+		{"", "FIXED_TIME | INFO  | done | req: {path=/a\\nforged, status=200, took=1.5s, " +
+			"client={seen=2026-01-15T10:30:00.000Z, tls=true}} | hops: [edge, 2026-01-15T10:30:00.000Z, {w=0.5}]\n"},
+		{NestedJSON, "FIXED_TIME | INFO  | done | req: {\"path\":\"/a\\nforged\",\"status\":200,\"took\":\"1.5s\"," +
+			"\"client\":{\"seen\":\"2026-01-15T10:30:00.000Z\",\"tls\":true}} | " +
+			"hops: [\"edge\",\"2026-01-15T10:30:00.000Z\",{\"w\":0.5}]\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		cfg := NewDefaultConfig()
+		cfg.ConsoleWriter = &buf
+		cfg.NoColor = true
+		cfg.NestedFormat = tt.format
+		cfg.ConsoleConfig.EncodeTime = func(t2 time.Time, enc zapcore.PrimitiveArrayEncoder) {
+			if t2.Equal(at) {
+				zapcore.ISO8601TimeEncoder(t2, enc)
+				return
+			}
+			enc.AppendString("FIXED_TIME")
+		}
+		logger, err := NewConsoleLogger("info", &cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger.Info("done", "req", req, "hops", hops)
+		_ = logger.Sync()
+
+		if got := buf.String(); got != tt.want {
+			t.Errorf("format %q mismatch:\ngot:  %q\nwant: %q", tt.format, got, tt.want)
+		}
+		if tt.format == NestedJSON {
+			for _, part := range strings.Split(strings.TrimSpace(buf.String()), " | ")[3:] {
+				_, val, _ := strings.Cut(part, ": ")
+				if !json.Valid([]byte(val)) {
+					t.Errorf("invalid inline JSON: %s", val)
+				}
+			}
+		}
+	}
+}
+
+// TestGoldenTextReflectedValues locks in the rendering of maps, structs and slices logged
+// through reflection, which nest and escape like marshalers in both NestedFormat modes.
+func TestGoldenTextReflectedValues(t *testing.T) {
+	type point struct {
+		X    int            `json:"x"`
+		Tags map[string]int `json:"tags"`
+	}
+
+	tests := []struct {
+		format NestedFormat
+		want   string
+	}{
+		{"", "FIXED_TIME | INFO  | done | w: {env=a\\rb} | m: {a=1, b={c=2}} | p: {x=1, tags={k=1}} | " +
+			"s: [a\\nb, 1, <nil>]\n"},
+		{NestedJSON, "FIXED_TIME | INFO  | done | w: {\"env\":\"a\\rb\"} | m: {\"a\":1,\"b\":{\"c\":2}} | " +
+			"p: {\"x\":1,\"tags\":{\"k\":1}} | s: [\"a\\nb\",1,\"<nil>\"]\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		cfg := NewDefaultConfig()
+		cfg.ConsoleWriter = &buf
+		cfg.NoColor = true
+		cfg.NestedFormat = tt.format
+		cfg.ConsoleConfig.EncodeTime = func(_ time.Time, enc zapcore.PrimitiveArrayEncoder) {
+			enc.AppendString("FIXED_TIME")
+		}
+		logger, err := NewConsoleLogger("info", &cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger = logger.WithFields(zap.Any("w", map[string]string{"env": "a\rb"}))
+		logger.Info("done",
+			zap.Any("m", map[string]any{"b": map[string]int{"c": 2}, "a": 1}),
+			zap.Any("p", point{X: 1, Tags: map[string]int{"k": 1}}),
+			zap.Any("s", []any{"a\nb", 1, nil}))
+		_ = logger.Sync()
+
+		if got := buf.String(); got != tt.want {
+			t.Errorf("format %q mismatch:\ngot:  %q\nwant: %q", tt.format, got, tt.want)
+		}
+	}
+}
+
 // TestGoldenJSONFormat locks in the JSON file format contract.
 func TestGoldenJSONFormat(t *testing.T) {
 	withTempLogFile(t, func(path string, cfg *Config) {
@@ -686,3 +849,100 @@ func TestWithContextOTel(t *testing.T) {
 		t.Error("span_id not extracted from OTel span context")
 	}
 }
+
+// prettyTestLogger returns a pretty console logger writing to buf with a fixed timestamp.
+func prettyTestLogger(t *testing.T, buf *bytes.Buffer, noColor bool) *Logger {
+	t.Helper()
+	cfg := NewDefaultConfig()
+	cfg.ConsoleWriter = buf
+	cfg.ConsoleFormat = LogFormatPretty
+	cfg.NoColor = noColor
+	cfg.ForceColor = !noColor
+	cfg.ConsoleConfig.EncodeTime = func(t2 time.Time, enc zapcore.PrimitiveArrayEncoder) {
+		enc.AppendString("FIXED_TIME")
+	}
+	logger, err := NewConsoleLogger("info", &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+// TestGoldenPrettyFormat locks in the multi-line development console format.
+func TestGoldenPrettyFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := prettyTestLogger(t, &buf, true)
+
+	req := zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		enc.AddString("method", "GET")
+		enc.AddInt("status", 200)
+		return enc.AddObject("client", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddString("ip", "10.0.0.1")
+			return nil
+		}))
+	})
+	tags := zapcore.ArrayMarshalerFunc(func(enc zapcore.ArrayEncoder) error {
+		enc.AppendString("a")
+		enc.AppendString("b")
+		return nil
+	})
+	logger.WithService("api").WithFields("request_id", "r-1").Info("handled", "request", req, "tags", tags, "ok", true)
+	_ = logger.Sync()
+
+	want := "FIXED_TIME | INFO  | [api] handled\n" +
+		"    request_id: r-1\n" +
+		"    request   : {\n" +
+		"        method: GET\n" +
+		"        status: 200\n" +
+		"        client: {\n" +
+		"            ip: 10.0.0.1\n" +
+		"        }\n" +
+		"    }\n" +
+		"    tags      : [a, b]\n" +
+		"    ok        : true\n"
+	if got := buf.String(); got != want {
+		t.Errorf("golden pretty format mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// TestPrettyFormatKeyInjection checks field keys cannot forge a line of their own.
+func TestPrettyFormatKeyInjection(t *testing.T) {
+	var buf bytes.Buffer
+	logger := prettyTestLogger(t, &buf, true)
+	logger.Info("handled", "user\nFIXED_TIME | ERROR | forged", "x")
+	_ = logger.Sync()
+
+	want := "FIXED_TIME | INFO  | handled\n" +
+		"    user\\nFIXED_TIME | ERROR | forged: x\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+// TestPrettyFormatColorsAndStack checks type colors and stack highlighting.
+func TestPrettyFormatColorsAndStack(t *testing.T) {
+	cfg := NewDefaultConfig()
+	enc := newDSPrettyEncoder(&cfg, cfg.ConsoleConfig, "")
+	enc.theme = DarkTheme()
+	buf, err := enc.EncodeEntry(zapcore.Entry{
+		Level:   zapcore.ErrorLevel,
+		Message: "boom\ninjected",
+		Stack:   "main.run\n\t/src/main.go:12",
+	}, []zapcore.Field{{Key: "count", Type: zapcore.Int64Type, Integer: 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		ansiBlue + "count" + ansiReset,
+		ansiMagenta + "3" + ansiReset,
+		ansiBold + ansiRed + "main.run" + ansiReset,
+		ansiDim + "/src/main.go:12" + ansiReset,
+		`boom\ninjected`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%q", want, out)
+		}
+	}
+}
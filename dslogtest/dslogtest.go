@@ -0,0 +1,243 @@
+// Package dslogtest provides a dslogger Logger recording its entries in memory, for tests
+// asserting on what was logged instead of matching console output:
+//
+//	logger, logs := dslogtest.New(t, "debug")
+//	svc := NewService(logger)
+//	svc.Handle(req)
+//	if logs.FilterMessage("order placed").FilterField("order_id", 42).Len() != 1 {
+//		t.Error("order not logged")
+//	}
+//
+// The entries are written to t's log when the test fails.
+//
+// The recording core is attached with dslogger.WithSink, so it sees entries after the
+// logger's level gate and field merging. The "service" field sinks receive becomes the
+// Service of the entry.
+package dslogtest
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/K4rian/dslogger"
+)
+
+// Entry is a recorded log entry.
+type Entry struct {
+	Level   zapcore.Level
+	Time    time.Time
+	Message string
+	Service string
+	Caller  zapcore.EntryCaller
+	Fields  []zap.Field // logger and call fields, without the service
+}
+
+// ContextMap returns the fields of the entry as a map, encoded like zap's JSON encoder
+// does: numbers as int64, uint64 or float64, nested values as maps and slices.
+func (e Entry) ContextMap() map[string]any {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range e.Fields {
+		f.AddTo(enc)
+	}
+	return enc.Fields
+}
+
+// HasField reports whether the entry has a field key with value, compared as
+// zap.Any(key, value) and errors by message.
+func (e Entry) HasField(key string, value any) bool {
+	if err, ok := value.(error); ok {
+		v, ok := e.ContextMap()[key]
+		return ok && v == err.Error()
+	}
+	want := zap.Any(key, value)
+	return slices.ContainsFunc(e.Fields, want.Equals)
+}
+
+// String renders the entry on one line, for test logs.
+func (e Entry) String() string {
+	var b strings.Builder
+	b.WriteString(e.Level.CapitalString())
+	if e.Service != "" {
+		b.WriteString(" [" + e.Service + "]")
+	}
+	b.WriteString(" " + e.Message)
+	m := e.ContextMap()
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		fmt.Fprintf(&b, " %s=%v", k, m[k])
+	}
+	if e.Caller.Defined {
+		b.WriteString(" (" + e.Caller.TrimmedPath() + ")")
+	}
+	return b.String()
+}
+
+// Logs is a set of recorded entries, safe for concurrent use. The Filter methods return
+// snapshots that do not see later entries.
+type Logs struct {
+	mu      sync.RWMutex
+	entries []Entry
+}
+
+// Len returns the number of entries.
+func (o *Logs) Len() int {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return len(o.entries)
+}
+
+// All returns a copy of the entries.
+func (o *Logs) All() []Entry {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return slices.Clone(o.entries)
+}
+
+// TakeAll returns the entries and removes them.
+func (o *Logs) TakeAll() []Entry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	entries := o.entries
+	o.entries = nil
+	return entries
+}
+
+// Filter returns the entries for which keep returns true.
+func (o *Logs) Filter(keep func(Entry) bool) *Logs {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	var entries []Entry
+	for _, e := range o.entries {
+		if keep(e) {
+			entries = append(entries, e)
+		}
+	}
+	return &Logs{entries: entries}
+}
+
+// FilterMessage returns the entries with message msg.
+func (o *Logs) FilterMessage(msg string) *Logs {
+	return o.Filter(func(e Entry) bool { return e.Message == msg })
+}
+
+// FilterMessageSnippet returns the entries whose message contains snippet.
+func (o *Logs) FilterMessageSnippet(snippet string) *Logs {
+	return o.Filter(func(e Entry) bool { return strings.Contains(e.Message, snippet) })
+}
+
+// FilterField returns the entries having a field key with value, see Entry.HasField.
+func (o *Logs) FilterField(key string, value any) *Logs {
+	return o.Filter(func(e Entry) bool { return e.HasField(key, value) })
+}
+
+// FilterFieldKey returns the entries having a field key, whatever its value.
+func (o *Logs) FilterFieldKey(key string) *Logs {
+	return o.Filter(func(e Entry) bool {
+		_, ok := e.ContextMap()[key]
+		return ok
+	})
+}
+
+// FilterLevelAtLeast returns the entries at lvl or above.
+func (o *Logs) FilterLevelAtLeast(lvl zapcore.Level) *Logs {
+	return o.Filter(func(e Entry) bool { return e.Level >= lvl })
+}
+
+// FilterService returns the entries of the service name.
+func (o *Logs) FilterService(name string) *Logs {
+	return o.Filter(func(e Entry) bool { return e.Service == name })
+}
+
+// add records an entry.
+func (o *Logs) add(e Entry) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.entries = append(o.entries, e)
+}
+
+// New returns a Logger at level recording its entries in the returned Logs, with the
+// default configuration and no console output. The entries are written to tb's log when
+// the test fails.
+func New(tb testing.TB, level string, opts ...dslogger.Option) (*dslogger.Logger, *Logs) {
+	tb.Helper()
+	return NewWithConfig(tb, level, nil, opts...)
+}
+
+// NewWithConfig is New with a configuration, for instance with a StacktraceLevel. The
+// console output is discarded unless cfg.ConsoleWriter is set. A nil cfg is the default
+// configuration.
+func NewWithConfig(tb testing.TB, level string, cfg *dslogger.Config, opts ...dslogger.Option) (*dslogger.Logger, *Logs) {
+	tb.Helper()
+	c := dslogger.NewDefaultConfig()
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.ConsoleWriter == nil {
+		c.ConsoleWriter = io.Discard
+	}
+
+	logs := &Logs{}
+	logger, err := dslogger.NewConsoleLogger(level, &c, append([]dslogger.Option{dslogger.WithSink(&core{logs: logs})}, opts...)...)
+	if err != nil {
+		tb.Fatalf("dslogtest: %v", err)
+	}
+	tb.Cleanup(func() {
+		if !tb.Failed() {
+			return
+		}
+		for _, e := range logs.All() {
+			tb.Log(e.String())
+		}
+	})
+	return logger, logs
+}
+
+// core is the zapcore.Core recording entries to logs. The logger gates the levels.
+type core struct {
+	logs   *Logs
+	fields []zap.Field
+}
+
+// Enabled implements zapcore.Core.
+func (c *core) Enabled(zapcore.Level) bool { return true }
+
+// With implements zapcore.Core.
+func (c *core) With(fields []zap.Field) zapcore.Core {
+	return &core{logs: c.logs, fields: append(slices.Clip(c.fields), fields...)}
+}
+
+// Check implements zapcore.Core.
+func (c *core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, c)
+}
+
+// Write implements zapcore.Core.
+func (c *core) Write(ent zapcore.Entry, fields []zap.Field) error {
+	e := Entry{
+		Level:   ent.Level,
+		Time:    ent.Time,
+		Message: ent.Message,
+		Caller:  ent.Caller,
+		Fields:  make([]zap.Field, 0, len(c.fields)+len(fields)),
+	}
+	for _, f := range slices.Concat(c.fields, fields) {
+		if f.Key == "service" && f.Type == zapcore.StringType {
+			e.Service = f.String
+			continue
+		}
+		e.Fields = append(e.Fields, f)
+	}
+	c.logs.add(e)
+	return nil
+}
+
+// Sync implements zapcore.Core.
+func (c *core) Sync() error { return nil }
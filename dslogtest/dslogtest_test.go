@@ -0,0 +1,114 @@
+package dslogtest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/K4rian/dslogger"
+)
+
+// TestRecordedEntries checks the recorded level, message, service, caller and fields.
+func TestRecordedEntries(t *testing.T) {
+	logger, logs := New(t, "info", dslogger.WithCustomFields("env", "test"))
+
+	logger.Debug("dropped")
+	logger.WithService("orders").Info("order placed", "order_id", 42, "items", []string{"a", "b"})
+	logger.Err(errors.New("card declined"), "payment failed", "order_id", 43)
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("entries = %v", entries)
+	}
+	placed := entries[0]
+	if placed.Level != zapcore.InfoLevel || placed.Message != "order placed" || placed.Service != "orders" {
+		t.Errorf("entry = %+v", placed)
+	}
+	if !placed.Caller.Defined || !strings.HasSuffix(placed.Caller.File, "dslogtest_test.go") {
+		t.Errorf("caller = %v", placed.Caller)
+	}
+	if m := placed.ContextMap(); m["env"] != "test" || m["order_id"] != int64(42) || m["service"] != nil {
+		t.Errorf("fields = %v", m)
+	}
+	if !placed.HasField("items", []string{"a", "b"}) || entries[1].Service != "" {
+		t.Errorf("entries = %v", entries)
+	}
+}
+
+// TestFilters checks the filter helpers and that filters are snapshots.
+func TestFilters(t *testing.T) {
+	logger, logs := New(t, "debug")
+
+	logger.Debug("cache miss", "key", "user:1")
+	logger.Info("request served", "status", 200)
+	logger.Warn("request slow", "status", 200, "ms", 950)
+	logger.Error("request failed", "status", 500, "error", errors.New("timeout"))
+	logger.WithService("worker").Info("job done")
+
+	tests := []struct {
+		name string
+		got  *Logs
+		want int
+	}{
+		{"message", logs.FilterMessage("request slow"), 1},
+		{"snippet", logs.FilterMessageSnippet("request"), 3},
+		{"field", logs.FilterField("status", 200), 2},
+		{"error field", logs.FilterField("error", errors.New("timeout")), 1},
+		{"field key", logs.FilterFieldKey("status"), 3},
+		{"level", logs.FilterLevelAtLeast(zapcore.WarnLevel), 2},
+		{"service", logs.FilterService("worker"), 1},
+		{"chained", logs.FilterMessageSnippet("request").FilterLevelAtLeast(zapcore.WarnLevel).FilterField("status", 500), 1},
+	}
+	for _, tt := range tests {
+		if n := tt.got.Len(); n != tt.want {
+			t.Errorf("%s: %d entries, want %d", tt.name, n, tt.want)
+		}
+	}
+
+	snapshot := logs.FilterLevelAtLeast(zapcore.DebugLevel)
+	logger.Info("later")
+	if snapshot.Len() != 5 || logs.Len() != 6 {
+		t.Errorf("snapshot = %d, logs = %d", snapshot.Len(), logs.Len())
+	}
+	if taken := logs.TakeAll(); len(taken) != 6 || logs.Len() != 0 {
+		t.Errorf("taken = %d, left = %d", len(taken), logs.Len())
+	}
+}
+
+// recordingTB is a testing.TB whose failure state is set by the test and whose logs and
+// cleanups are recorded.
+type recordingTB struct {
+	testing.TB
+	failed   bool
+	logged   []string
+	cleanups []func()
+}
+
+func (tb *recordingTB) Helper()           {}
+func (tb *recordingTB) Failed() bool      { return tb.failed }
+func (tb *recordingTB) Log(args ...any)   { tb.logged = append(tb.logged, args[0].(string)) }
+func (tb *recordingTB) Cleanup(fn func()) { tb.cleanups = append(tb.cleanups, fn) }
+
+// TestDumpOnFailure checks the entries reach the test log only when the test failed.
+func TestDumpOnFailure(t *testing.T) {
+	for _, failed := range []bool{false, true} {
+		tb := &recordingTB{TB: t, failed: failed}
+		logger, _ := New(tb, "info")
+		logger.WithService("api").Warn("disk low", "free", "5%")
+		for _, fn := range tb.cleanups {
+			fn()
+		}
+
+		if !failed {
+			if len(tb.logged) != 0 {
+				t.Errorf("logged on success: %q", tb.logged)
+			}
+			continue
+		}
+		if len(tb.logged) != 1 || !strings.HasPrefix(tb.logged[0], "WARN [api] disk low free=5% (dslogtest/dslogtest_test.go:") {
+			t.Errorf("logged = %q", tb.logged)
+		}
+	}
+}
@@ -2,9 +2,9 @@ package dslogger
 
 import (
 	"fmt"
+	"runtime"
 	"slices"
 	"strconv"
-	"strings"
 	"time"
 
 	"go.uber.org/zap/buffer"
@@ -15,8 +15,9 @@ var _pool = buffer.NewPool()
 
 // kvPair holds a pre-formatted key-value pair accumulated by With() calls
 type kvPair struct {
-	key string
-	val string
+	key  string
+	val  string
+	kind valueKind
 }
 
 // dsConsoleEncoder is a custom zapcore.Encoder that formats console and text-file
@@ -29,6 +30,7 @@ type dsConsoleEncoder struct {
 	serviceName string
 	pairs       []kvPair // context fields from With()
 	ns          string   // current namespace prefix (from OpenNamespace)
+	theme       *Theme   // plainTheme unless console colors are enabled
 }
 
 // newDSConsoleEncoder creates a dsConsoleEncoder.
@@ -37,126 +39,128 @@ func newDSConsoleEncoder(cfg *Config, encCfg zapcore.EncoderConfig, serviceName
 		cfg:         cfg,
 		encCfg:      encCfg,
 		serviceName: serviceName,
+		theme:       plainTheme,
 	}
 }
 
 // add appends a pre-formatted key-value pair, applying the current namespace prefix.
-func (e *dsConsoleEncoder) add(key, val string) {
-	e.pairs = append(e.pairs, kvPair{e.ns + key, val})
+func (e *dsConsoleEncoder) add(key, val string, kind valueKind) {
+	e.pairs = append(e.pairs, kvPair{e.ns + key, val, kind})
 }
 
 // ---------------------------------------------------------------------------
 // ObjectEncoder (With)
 func (e *dsConsoleEncoder) AddArray(key string, v zapcore.ArrayMarshaler) error {
-	arr := &sliceArrayEncoder{}
+	arr := &treeArrayEncoder{encCfg: &e.encCfg}
 	if err := v.MarshalLogArray(arr); err != nil {
 		return err
 	}
-	e.add(key, "["+strings.Join(arr.elems, ", ")+"]")
+	e.add(key, inlineText(treeValue{kind: kindArray, elems: arr.elems}, e.cfg.NestedFormat), kindArray)
 	return nil
 }
 
 func (e *dsConsoleEncoder) AddObject(key string, v zapcore.ObjectMarshaler) error {
-	m := zapcore.NewMapObjectEncoder()
-	if err := v.MarshalLogObject(m); err != nil {
+	obj := newTreeObjectEncoder(&e.encCfg)
+	if err := v.MarshalLogObject(obj); err != nil {
 		return err
 	}
-	e.add(key, fmt.Sprint(m.Fields))
+	e.add(key, inlineText(treeValue{kind: kindObject, obj: obj.fields}, e.cfg.NestedFormat), kindObject)
 	return nil
 }
 
 func (e *dsConsoleEncoder) AddBinary(k string, v []byte) {
-	e.add(k, fmt.Sprint(v))
+	e.add(k, fmt.Sprint(v), kindOther)
 }
 
 func (e *dsConsoleEncoder) AddByteString(k string, v []byte) {
-	e.add(k, string(v))
+	e.add(k, string(v), kindString)
 }
 
 func (e *dsConsoleEncoder) AddBool(k string, v bool) {
-	e.add(k, strconv.FormatBool(v))
+	e.add(k, strconv.FormatBool(v), kindBool)
 }
 
 func (e *dsConsoleEncoder) AddComplex128(k string, v complex128) {
-	e.add(k, fmt.Sprint(v))
+	e.add(k, fmt.Sprint(v), kindNumber)
 }
 
 func (e *dsConsoleEncoder) AddComplex64(k string, v complex64) {
-	e.add(k, fmt.Sprint(v))
+	e.add(k, fmt.Sprint(v), kindNumber)
 }
 
 func (e *dsConsoleEncoder) AddDuration(k string, v time.Duration) {
-	e.add(k, v.String())
+	e.add(k, encodeDurationString(&e.encCfg, v), kindDuration)
 }
 
 func (e *dsConsoleEncoder) AddFloat64(k string, v float64) {
-	e.add(k, strconv.FormatFloat(v, 'f', -1, 64))
+	e.add(k, strconv.FormatFloat(v, 'f', -1, 64), kindNumber)
 }
 
 func (e *dsConsoleEncoder) AddFloat32(k string, v float32) {
-	e.add(k, strconv.FormatFloat(float64(v), 'f', -1, 32))
+	e.add(k, strconv.FormatFloat(float64(v), 'f', -1, 32), kindNumber)
 }
 
 func (e *dsConsoleEncoder) AddInt(k string, v int) {
-	e.add(k, strconv.Itoa(v))
+	e.add(k, strconv.Itoa(v), kindNumber)
 }
 
 func (e *dsConsoleEncoder) AddInt64(k string, v int64) {
-	e.add(k, strconv.FormatInt(v, 10))
+	e.add(k, strconv.FormatInt(v, 10), kindNumber)
 }
 
 func (e *dsConsoleEncoder) AddInt32(k string, v int32) {
-	e.add(k, strconv.FormatInt(int64(v), 10))
+	e.add(k, strconv.FormatInt(int64(v), 10), kindNumber)
 }
 
 func (e *dsConsoleEncoder) AddInt16(k string, v int16) {
-	e.add(k, strconv.FormatInt(int64(v), 10))
+	e.add(k, strconv.FormatInt(int64(v), 10), kindNumber)
 }
 
 func (e *dsConsoleEncoder) AddInt8(k string, v int8) {
-	e.add(k, strconv.FormatInt(int64(v), 10))
+	e.add(k, strconv.FormatInt(int64(v), 10), kindNumber)
 }
 
 func (e *dsConsoleEncoder) AddString(k string, v string) {
-	e.add(k, sanitizeLogString(v))
+	e.add(k, sanitizeLogString(v), kindString)
 }
 
 func (e *dsConsoleEncoder) AddTime(k string, v time.Time) {
 	enc := &singleValueEncoder{}
 	if e.encCfg.EncodeTime != nil {
 		e.encCfg.EncodeTime(v, enc)
-		e.add(k, enc.val)
+		e.add(k, enc.val, kindTime)
 	} else {
-		e.add(k, v.String())
+		e.add(k, v.String(), kindTime)
 	}
 }
 
 func (e *dsConsoleEncoder) AddUint(k string, v uint) {
-	e.add(k, strconv.FormatUint(uint64(v), 10))
+	e.add(k, strconv.FormatUint(uint64(v), 10), kindNumber)
 }
 
 func (e *dsConsoleEncoder) AddUint64(k string, v uint64) {
-	e.add(k, strconv.FormatUint(v, 10))
+	e.add(k, strconv.FormatUint(v, 10), kindNumber)
 }
 
 func (e *dsConsoleEncoder) AddUint32(k string, v uint32) {
-	e.add(k, strconv.FormatUint(uint64(v), 10))
+	e.add(k, strconv.FormatUint(uint64(v), 10), kindNumber)
 }
 
 func (e *dsConsoleEncoder) AddUint16(k string, v uint16) {
-	e.add(k, strconv.FormatUint(uint64(v), 10))
+	e.add(k, strconv.FormatUint(uint64(v), 10), kindNumber)
 }
 
 func (e *dsConsoleEncoder) AddUint8(k string, v uint8) {
-	e.add(k, strconv.FormatUint(uint64(v), 10))
+	e.add(k, strconv.FormatUint(uint64(v), 10), kindNumber)
 }
 
 func (e *dsConsoleEncoder) AddUintptr(k string, v uintptr) {
-	e.add(k, strconv.FormatUint(uint64(v), 10))
+	e.add(k, strconv.FormatUint(uint64(v), 10), kindNumber)
 }
 
 func (e *dsConsoleEncoder) AddReflected(k string, v any) error {
-	e.add(k, fmt.Sprint(v))
+	tv := reflectedTreeValue(v)
+	e.add(k, e.treeText(tv), tv.kind)
 	return nil
 }
 
@@ -177,6 +181,7 @@ func (e *dsConsoleEncoder) Clone() zapcore.Encoder {
 		serviceName: e.serviceName,
 		pairs:       slices.Clone(e.pairs),
 		ns:          e.ns,
+		theme:       e.theme,
 	}
 }
 
@@ -189,35 +194,107 @@ func (e *dsConsoleEncoder) Clone() zapcore.Encoder {
 func (e *dsConsoleEncoder) EncodeEntry(entry zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
 	buf := _pool.Get()
 	sep := e.cfg.ConsoleSeparator
+	appendEntryHeader(buf, e.cfg, e.encCfg, e.serviceName, e.theme, entry, len(e.pairs) > 0 || len(fields) > 0)
+
+	// Fields: context (from With) then per-call
+	fieldSep := e.cfg.FieldSeparator
+	for _, p := range e.pairs {
+		buf.AppendString(sep)
+		paint(buf, e.theme.Key, p.key)
+		buf.AppendString(fieldSep)
+		paint(buf, e.theme.valueColor(p.kind), p.val)
+	}
+	var stacks [][]runtime.Frame
+	for _, f := range fields {
+		switch f.Type {
+		case zapcore.SkipType:
+			continue
+		case zapcore.InlineMarshalerType:
+			// Inline objects (error fields among them) contribute their own keys
+			inline := newDSConsoleEncoder(e.cfg, e.encCfg, "")
+			f.AddTo(inline)
+			for _, p := range inline.pairs {
+				buf.AppendString(sep)
+				paint(buf, e.theme.Key, p.key)
+				buf.AppendString(fieldSep)
+				paint(buf, e.theme.valueColor(p.kind), p.val)
+			}
+			if frames := fieldStack(f); len(frames) > 0 {
+				stacks = append(stacks, frames)
+			}
+			continue
+		}
+		buf.AppendString(sep)
+		paint(buf, e.theme.Key, f.Key)
+		buf.AppendString(fieldSep)
+		paint(buf, e.theme.valueColor(fieldKind(f)), e.formatField(f))
+	}
+
+	// Stack traces: the entry's, then those captured by dslogger or carried by errors
+	if entry.Stack != "" {
+		buf.AppendByte('\n')
+		buf.AppendString(entry.Stack)
+	}
+	for _, frames := range stacks {
+		buf.AppendByte('\n')
+		buf.AppendString(formatFramesCompact(frames))
+	}
+
+	buf.AppendByte('\n')
+	return buf, nil
+}
+
+// appendEntryHeader writes the part of a text line shared by the console formats:
+//
+//	TIMESTAMP<sep>LEVEL<sep>CALLER<sep>FUNCTION<sep>[SERVICE] MESSAGE
+//
+// Disabled elements (empty key or nil encoder) are skipped along with their separator.
+// The level keeps the color of its LevelFormat, the other elements are painted with theme.
+// The caller, service and message columns are fitted to Config.CallerWidth, ServiceWidth
+// and MessageWidth. The message is only padded when padMessage is set, so lines without
+// fields never end in blanks.
+func appendEntryHeader(buf *buffer.Buffer, cfg *Config, encCfg zapcore.EncoderConfig, serviceName string, theme *Theme, entry zapcore.Entry, padMessage bool) {
+	sep := cfg.ConsoleSeparator
 	needsSep := false
 
 	// Timestamp
-	if e.encCfg.TimeKey != "" && e.encCfg.EncodeTime != nil {
+	if encCfg.TimeKey != "" && encCfg.EncodeTime != nil {
 		enc := &singleValueEncoder{}
-		e.encCfg.EncodeTime(entry.Time, enc)
-		buf.AppendString(enc.val)
+		encCfg.EncodeTime(entry.Time, enc)
+		paint(buf, theme.Timestamp, enc.val)
 		needsSep = true
 	}
 
 	// Level
-	if e.encCfg.LevelKey != "" && e.encCfg.EncodeLevel != nil {
+	if encCfg.LevelKey != "" && encCfg.EncodeLevel != nil {
 		if needsSep {
 			buf.AppendString(sep)
 		}
 		enc := &singleValueEncoder{}
-		e.encCfg.EncodeLevel(entry.Level, enc)
+		encCfg.EncodeLevel(entry.Level, enc)
 		buf.AppendString(enc.val)
 		needsSep = true
 	}
 
 	// Caller
-	if entry.Caller.Defined && e.encCfg.CallerKey != "" && e.encCfg.EncodeCaller != nil {
+	if entry.Caller.Defined && encCfg.CallerKey != "" && encCfg.EncodeCaller != nil {
 		if needsSep {
 			buf.AppendString(sep)
 		}
 		enc := &singleValueEncoder{}
-		e.encCfg.EncodeCaller(entry.Caller, enc)
-		buf.AppendString(enc.val)
+		encCfg.EncodeCaller(entry.Caller, enc)
+		caller := truncateLeft(enc.val, cfg.CallerWidth)
+		paint(buf, theme.Caller, caller)
+		buf.AppendString(padding(caller, cfg.CallerWidth))
+		needsSep = true
+	}
+
+	// Function
+	if entry.Caller.Defined && entry.Caller.Function != "" && encCfg.FunctionKey != "" {
+		if needsSep {
+			buf.AppendString(sep)
+		}
+		paint(buf, theme.Caller, shortFuncName(entry.Caller.Function))
 		needsSep = true
 	}
 
@@ -225,68 +302,102 @@ func (e *dsConsoleEncoder) EncodeEntry(entry zapcore.Entry, fields []zapcore.Fie
 	if needsSep {
 		buf.AppendString(sep)
 	}
-	if e.serviceName != "" {
-		buf.AppendString(e.cfg.ServiceNameDecorators[0])
-		buf.AppendString(e.serviceName)
-		buf.AppendString(e.cfg.ServiceNameDecorators[1])
+	if serviceName != "" {
+		service := fitService(cfg, serviceName)
+		paint(buf, theme.Service, service)
+		buf.AppendString(padding(service, cfg.ServiceWidth))
 		buf.AppendByte(' ')
+	} else if cfg.ServiceWidth > 0 {
+		// Keep messages aligned with those of loggers that have a service name.
+		buf.AppendString(padding("", cfg.ServiceWidth+1))
 	}
-	buf.AppendString(sanitizeLogString(entry.Message))
-
-	// Fields: context (from With) then per-call
-	fieldSep := e.cfg.FieldSeparator
-	for _, p := range e.pairs {
-		buf.AppendString(sep)
-		buf.AppendString(p.key)
-		buf.AppendString(fieldSep)
-		buf.AppendString(p.val)
-	}
-	for _, f := range fields {
-		if f.Type == zapcore.SkipType {
-			continue
-		}
-		buf.AppendString(sep)
-		buf.AppendString(f.Key)
-		buf.AppendString(fieldSep)
-		buf.AppendString(formatField(f))
+	msg := truncateRight(sanitizeLogString(entry.Message), cfg.MessageWidth)
+	paint(buf, theme.Messages[entry.Level], msg)
+	if padMessage {
+		buf.AppendString(padding(msg, cfg.MessageWidth))
 	}
+}
 
-	// Stack trace
-	if entry.Stack != "" {
-		buf.AppendByte('\n')
-		buf.AppendString(entry.Stack)
+// fitService decorates serviceName and fits it to cfg.ServiceWidth, truncating the
+// name rather than its decorators.
+func fitService(cfg *Config, serviceName string) string {
+	open, closing := cfg.ServiceNameDecorators[0], cfg.ServiceNameDecorators[1]
+	if cfg.ServiceWidth > 0 {
+		room := max(cfg.ServiceWidth-displayWidth(open)-displayWidth(closing), 1)
+		serviceName = truncateLeft(serviceName, room)
 	}
-
-	buf.AppendByte('\n')
-	return buf, nil
+	return open + serviceName + closing
 }
 
-// formatField renders a zapcore.Field value as a console-friendly string
-func formatField(f zapcore.Field) string {
+// formatField renders a zapcore.Field value as a console-friendly string.
+// Arrays, objects and reflected values are rendered in cfg.NestedFormat with the
+// encoder's time and duration encoders applied at every depth.
+func (e *dsConsoleEncoder) formatField(f zapcore.Field) string {
 	switch f.Type {
 	case zapcore.ArrayMarshalerType:
-		arr := &sliceArrayEncoder{}
+		arr := &treeArrayEncoder{encCfg: &e.encCfg}
 		if m, ok := f.Interface.(zapcore.ArrayMarshaler); ok {
 			_ = m.MarshalLogArray(arr)
 		}
-		return "[" + strings.Join(arr.elems, ", ") + "]"
+		return inlineText(treeValue{kind: kindArray, elems: arr.elems}, e.cfg.NestedFormat)
 	case zapcore.ObjectMarshalerType:
-		m := zapcore.NewMapObjectEncoder()
-		if obj, ok := f.Interface.(zapcore.ObjectMarshaler); ok {
-			_ = obj.MarshalLogObject(m)
+		obj := newTreeObjectEncoder(&e.encCfg)
+		if m, ok := f.Interface.(zapcore.ObjectMarshaler); ok {
+			_ = m.MarshalLogObject(obj)
 		}
-		return fmt.Sprint(m.Fields)
+		return inlineText(treeValue{kind: kindObject, obj: obj.fields}, e.cfg.NestedFormat)
 	case zapcore.StringType:
 		return sanitizeLogString(f.String)
-	default:
+	case zapcore.DurationType:
+		return encodeDurationString(&e.encCfg, time.Duration(f.Integer))
+	case zapcore.TimeType, zapcore.TimeFullType:
+		if t, ok := zapFieldValue(f).(time.Time); ok {
+			return encodeTimeString(&e.encCfg, t)
+		}
 		return fmt.Sprint(zapFieldValue(f))
+	case zapcore.ReflectType:
+		return e.treeText(reflectedTreeValue(f.Interface))
+	default:
+		return sanitizeLogString(fmt.Sprint(zapFieldValue(f)))
+	}
+}
+
+// treeText renders v for the line, objects and arrays in cfg.NestedFormat and scalars
+// sanitised.
+func (e *dsConsoleEncoder) treeText(v treeValue) string {
+	if v.kind == kindObject || v.kind == kindArray {
+		return inlineText(v, e.cfg.NestedFormat)
+	}
+	return sanitizeLogString(v.text)
+}
+
+// fieldKind classifies a per-call field for theme coloring.
+func fieldKind(f zapcore.Field) valueKind {
+	switch f.Type {
+	case zapcore.StringType, zapcore.ByteStringType, zapcore.StringerType:
+		return kindString
+	case zapcore.BoolType:
+		return kindBool
+	case zapcore.DurationType:
+		return kindDuration
+	case zapcore.TimeType, zapcore.TimeFullType:
+		return kindTime
+	case zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type,
+		zapcore.Uint64Type, zapcore.Uint32Type, zapcore.Uint16Type, zapcore.Uint8Type, zapcore.UintptrType,
+		zapcore.Float64Type, zapcore.Float32Type, zapcore.Complex128Type, zapcore.Complex64Type:
+		return kindNumber
+	case zapcore.ArrayMarshalerType:
+		return kindArray
+	case zapcore.ObjectMarshalerType:
+		return kindObject
+	default:
+		return kindOther
 	}
 }
 
 // ---------------------------------------------------------------------------
-// Minimal PrimitiveArrayEncoder / ArrayEncoder implementations
-// used to capture single values from EncodeTime / EncodeLevel / EncodeCaller
-// and to format array-typed fields.
+// Minimal PrimitiveArrayEncoder implementation
+// used to capture single values from EncodeTime / EncodeLevel / EncodeCaller.
 
 // singleValueEncoder captures the last value appended by a PrimitiveArrayEncoder callback.
 type singleValueEncoder struct {
@@ -363,111 +474,3 @@ func (e *singleValueEncoder) AppendUint8(v uint8) {
 func (e *singleValueEncoder) AppendUintptr(v uintptr) {
 	e.val = strconv.FormatUint(uint64(v), 10)
 }
-
-// sliceArrayEncoder accumulates formatted elements for AddArray / MarshalLogArray.
-type sliceArrayEncoder struct {
-	elems []string
-}
-
-func (e *sliceArrayEncoder) AppendBool(v bool) {
-	e.elems = append(e.elems, strconv.FormatBool(v))
-}
-
-func (e *sliceArrayEncoder) AppendByteString(v []byte) {
-	e.elems = append(e.elems, string(v))
-}
-
-func (e *sliceArrayEncoder) AppendComplex128(v complex128) {
-	e.elems = append(e.elems, fmt.Sprint(v))
-}
-
-func (e *sliceArrayEncoder) AppendComplex64(v complex64) {
-	e.elems = append(e.elems, fmt.Sprint(v))
-}
-
-func (e *sliceArrayEncoder) AppendFloat64(v float64) {
-	e.elems = append(e.elems, strconv.FormatFloat(v, 'f', -1, 64))
-}
-
-func (e *sliceArrayEncoder) AppendFloat32(v float32) {
-	e.elems = append(e.elems, strconv.FormatFloat(float64(v), 'f', -1, 32))
-}
-
-func (e *sliceArrayEncoder) AppendInt(v int) {
-	e.elems = append(e.elems, strconv.Itoa(v))
-}
-
-func (e *sliceArrayEncoder) AppendInt64(v int64) {
-	e.elems = append(e.elems, strconv.FormatInt(v, 10))
-}
-
-func (e *sliceArrayEncoder) AppendInt32(v int32) {
-	e.elems = append(e.elems, strconv.FormatInt(int64(v), 10))
-}
-
-func (e *sliceArrayEncoder) AppendInt16(v int16) {
-	e.elems = append(e.elems, strconv.FormatInt(int64(v), 10))
-}
-
-func (e *sliceArrayEncoder) AppendInt8(v int8) {
-	e.elems = append(e.elems, strconv.FormatInt(int64(v), 10))
-}
-
-func (e *sliceArrayEncoder) AppendString(v string) {
-	e.elems = append(e.elems, v)
-}
-
-func (e *sliceArrayEncoder) AppendUint(v uint) {
-	e.elems = append(e.elems, strconv.FormatUint(uint64(v), 10))
-}
-
-func (e *sliceArrayEncoder) AppendUint64(v uint64) {
-	e.elems = append(e.elems, strconv.FormatUint(v, 10))
-}
-
-func (e *sliceArrayEncoder) AppendUint32(v uint32) {
-	e.elems = append(e.elems, strconv.FormatUint(uint64(v), 10))
-}
-
-func (e *sliceArrayEncoder) AppendUint16(v uint16) {
-	e.elems = append(e.elems, strconv.FormatUint(uint64(v), 10))
-}
-
-func (e *sliceArrayEncoder) AppendUint8(v uint8) {
-	e.elems = append(e.elems, strconv.FormatUint(uint64(v), 10))
-}
-
-func (e *sliceArrayEncoder) AppendUintptr(v uintptr) {
-	e.elems = append(e.elems, strconv.FormatUint(uint64(v), 10))
-}
-
-func (e *sliceArrayEncoder) AppendDuration(v time.Duration) {
-	e.elems = append(e.elems, v.String())
-}
-
-func (e *sliceArrayEncoder) AppendTime(v time.Time) {
-	e.elems = append(e.elems, v.String())
-}
-
-func (e *sliceArrayEncoder) AppendArray(v zapcore.ArrayMarshaler) error {
-	inner := &sliceArrayEncoder{}
-	if err := v.MarshalLogArray(inner); err != nil {
-		return err
-	}
-	e.elems = append(e.elems, "["+strings.Join(inner.elems, ", ")+"]")
-	return nil
-}
-
-func (e *sliceArrayEncoder) AppendObject(v zapcore.ObjectMarshaler) error {
-	m := zapcore.NewMapObjectEncoder()
-	if err := v.MarshalLogObject(m); err != nil {
-		return err
-	}
-	e.elems = append(e.elems, fmt.Sprint(m.Fields))
-	return nil
-}
-
-func (e *sliceArrayEncoder) AppendReflected(v any) error {
-	e.elems = append(e.elems, fmt.Sprint(v))
-	return nil
-}
@@ -0,0 +1,196 @@
+package dslogger
+
+import (
+	"strings"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// prettyIndent is the indentation unit for fields and stack frames in LogFormatPretty.
+const prettyIndent = "    "
+
+// prettyInlineArrayMax is the widest scalar-only array rendered on a single line.
+const prettyInlineArrayMax = 60
+
+// ANSI sequences used by the built-in themes.
+const (
+	ansiReset   = "\033[0m"
+	ansiBold    = "\033[1m"
+	ansiDim     = "\033[2m"
+	ansiRed     = "\033[31m"
+	ansiGreen   = "\033[32m"
+	ansiYellow  = "\033[33m"
+	ansiBlue    = "\033[34m"
+	ansiMagenta = "\033[35m"
+	ansiCyan    = "\033[36m"
+)
+
+// dsPrettyEncoder is the development console encoder selected by LogFormatPretty.
+// It writes the usual header line, then one indented field per line with aligned
+// keys, nested objects and arrays expanded, and the stack trace highlighted.
+// Keys and values are sanitised like in the single-line format, so a field can never
+// forge a line of its own.
+type dsPrettyEncoder struct {
+	*treeObjectEncoder // context fields from With()
+	cfg                *Config
+	encCfg             zapcore.EncoderConfig
+	serviceName        string
+	theme              *Theme // plainTheme when color is disabled
+}
+
+// newDSPrettyEncoder creates a dsPrettyEncoder.
+func newDSPrettyEncoder(cfg *Config, encCfg zapcore.EncoderConfig, serviceName string) *dsPrettyEncoder {
+	e := &dsPrettyEncoder{
+		cfg:         cfg,
+		encCfg:      encCfg,
+		serviceName: serviceName,
+		theme:       plainTheme,
+	}
+	e.treeObjectEncoder = newTreeObjectEncoder(&e.encCfg)
+	return e
+}
+
+// Clone implements zapcore.Encoder.
+func (e *dsPrettyEncoder) Clone() zapcore.Encoder {
+	return e.clonePretty()
+}
+
+func (e *dsPrettyEncoder) clonePretty() *dsPrettyEncoder {
+	c := &dsPrettyEncoder{
+		cfg:         e.cfg,
+		encCfg:      e.encCfg,
+		serviceName: e.serviceName,
+		theme:       e.theme,
+	}
+	c.treeObjectEncoder = e.treeObjectEncoder.clone()
+	c.treeObjectEncoder.encCfg = &c.encCfg
+	return c
+}
+
+// EncodeEntry formats one log entry.
+// Output format:
+//
+//	TIMESTAMP<sep>LEVEL<sep>CALLER<sep>[SERVICE] MESSAGE
+//	    key<fs>value
+//	    longer_key<fs>{
+//	        nested<fs>value
+//	    }
+//	    STACK
+func (e *dsPrettyEncoder) EncodeEntry(entry zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	buf := _pool.Get()
+	appendEntryHeader(buf, e.cfg, e.encCfg, e.serviceName, e.theme, entry, false)
+	buf.AppendByte('\n')
+
+	tree := e.treeObjectEncoder
+	if len(fields) > 0 {
+		tree = e.treeObjectEncoder.clone()
+		for _, f := range fields {
+			f.AddTo(tree)
+		}
+	}
+	e.appendFields(buf, tree.fields, 1)
+
+	if entry.Stack != "" {
+		e.appendStack(buf, entry.Stack)
+	}
+	for _, f := range fields {
+		if frames := fieldStack(f); len(frames) > 0 {
+			e.appendStack(buf, formatFrames(frames))
+		}
+	}
+	return buf, nil
+}
+
+// appendFields writes one field per line at the given depth, keys padded to a common width.
+func (e *dsPrettyEncoder) appendFields(buf *buffer.Buffer, fields []treeField, depth int) {
+	keys := make([]string, len(fields))
+	width := 0
+	for i, f := range fields {
+		keys[i] = sanitizeLogString(f.key)
+		width = max(width, displayWidth(keys[i]))
+	}
+	for i, f := range fields {
+		appendIndent(buf, depth)
+		paint(buf, e.theme.Key, keys[i])
+		buf.AppendString(strings.Repeat(" ", width-displayWidth(keys[i])))
+		buf.AppendString(e.cfg.FieldSeparator)
+		e.appendValue(buf, f.val, depth)
+		buf.AppendByte('\n')
+	}
+}
+
+// appendValue writes v, expanding objects and long or nested arrays over several lines.
+func (e *dsPrettyEncoder) appendValue(buf *buffer.Buffer, v treeValue, depth int) {
+	switch v.kind {
+	case kindObject:
+		if len(v.obj) == 0 {
+			buf.AppendString("{}")
+			return
+		}
+		buf.AppendString("{\n")
+		e.appendFields(buf, v.obj, depth+1)
+		appendIndent(buf, depth)
+		buf.AppendByte('}')
+	case kindArray:
+		if len(v.elems) == 0 {
+			buf.AppendString("[]")
+			return
+		}
+		if inlineArray(v.elems) {
+			buf.AppendByte('[')
+			for i, el := range v.elems {
+				if i > 0 {
+					buf.AppendString(", ")
+				}
+				e.appendValue(buf, el, depth)
+			}
+			buf.AppendByte(']')
+			return
+		}
+		buf.AppendString("[\n")
+		for _, el := range v.elems {
+			appendIndent(buf, depth+1)
+			e.appendValue(buf, el, depth+1)
+			buf.AppendByte('\n')
+		}
+		appendIndent(buf, depth)
+		buf.AppendByte(']')
+	default:
+		paint(buf, e.theme.valueColor(v.kind), sanitizeLogString(v.text))
+	}
+}
+
+// appendStack writes the stack trace indented, function names highlighted and
+// file:line locations dimmed.
+func (e *dsPrettyEncoder) appendStack(buf *buffer.Buffer, stack string) {
+	for _, line := range strings.Split(strings.TrimRight(stack, "\n"), "\n") {
+		appendIndent(buf, 1)
+		if loc, ok := strings.CutPrefix(line, "\t"); ok {
+			buf.AppendString(prettyIndent)
+			paint(buf, e.theme.StackLocation, loc)
+		} else {
+			paint(buf, e.theme.StackFunction, line)
+		}
+		buf.AppendByte('\n')
+	}
+}
+
+// inlineArray reports whether elems are all scalars short enough for one line.
+func inlineArray(elems []treeValue) bool {
+	n := 0
+	for _, el := range elems {
+		if el.kind == kindObject || el.kind == kindArray {
+			return false
+		}
+		n += len(el.text) + 2
+	}
+	return n <= prettyInlineArrayMax
+}
+
+// appendIndent writes depth indentation units.
+func appendIndent(buf *buffer.Buffer, depth int) {
+	for range depth {
+		buf.AppendString(prettyIndent)
+	}
+}
@@ -0,0 +1,506 @@
+package dslogger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// valueKind classifies a collected field value so renderers can color or quote it by type.
+type valueKind uint8
+
+const (
+	kindString valueKind = iota
+	kindNumber
+	kindBool
+	kindTime
+	kindDuration
+	kindOther
+	kindObject
+	kindArray
+)
+
+// treeValue is a typed, already-formatted field value. Objects and arrays keep their
+// children in insertion order so nested output is deterministic.
+type treeValue struct {
+	kind  valueKind
+	text  string      // scalar rendering, unsanitised: renderers escape it for their output
+	obj   []treeField // children when kind == kindObject
+	elems []treeValue // elements when kind == kindArray
+}
+
+// treeField is a key with its value.
+type treeField struct {
+	key string
+	val treeValue
+}
+
+// cloneTree deep-copies fields so clones never share nested backing arrays.
+func cloneTree(fields []treeField) []treeField {
+	out := slices.Clone(fields)
+	for i := range out {
+		out[i].val = out[i].val.clone()
+	}
+	return out
+}
+
+func (v treeValue) clone() treeValue {
+	v.obj = cloneTree(v.obj)
+	if v.elems != nil {
+		elems := slices.Clone(v.elems)
+		for i := range elems {
+			elems[i] = elems[i].clone()
+		}
+		v.elems = elems
+	}
+	return v
+}
+
+// treeObjectEncoder is a zapcore.ObjectEncoder collecting fields into an ordered tree.
+// OpenNamespace nests every subsequent field under a new object, as zap's JSON encoder does.
+type treeObjectEncoder struct {
+	encCfg *zapcore.EncoderConfig
+	fields []treeField
+	ns     []string // open namespaces, outermost first
+}
+
+// newTreeObjectEncoder returns an empty collector. encCfg supplies the time encoder.
+func newTreeObjectEncoder(encCfg *zapcore.EncoderConfig) *treeObjectEncoder {
+	return &treeObjectEncoder{encCfg: encCfg}
+}
+
+// clone returns an independent copy of the collector.
+func (e *treeObjectEncoder) clone() *treeObjectEncoder {
+	return &treeObjectEncoder{
+		encCfg: e.encCfg,
+		fields: cloneTree(e.fields),
+		ns:     slices.Clone(e.ns),
+	}
+}
+
+// target returns the field list of the innermost open namespace.
+func (e *treeObjectEncoder) target() *[]treeField {
+	cur := &e.fields
+	for _, name := range e.ns {
+		for i := len(*cur) - 1; i >= 0; i-- {
+			if (*cur)[i].key == name && (*cur)[i].val.kind == kindObject {
+				cur = &(*cur)[i].val.obj
+				break
+			}
+		}
+	}
+	return cur
+}
+
+func (e *treeObjectEncoder) add(key string, v treeValue) {
+	t := e.target()
+	*t = append(*t, treeField{key: key, val: v})
+}
+
+func (e *treeObjectEncoder) AddArray(key string, v zapcore.ArrayMarshaler) error {
+	arr := &treeArrayEncoder{encCfg: e.encCfg}
+	err := v.MarshalLogArray(arr)
+	e.add(key, treeValue{kind: kindArray, elems: arr.elems})
+	return err
+}
+
+func (e *treeObjectEncoder) AddObject(key string, v zapcore.ObjectMarshaler) error {
+	obj := newTreeObjectEncoder(e.encCfg)
+	err := v.MarshalLogObject(obj)
+	e.add(key, treeValue{kind: kindObject, obj: obj.fields})
+	return err
+}
+
+func (e *treeObjectEncoder) AddBinary(k string, v []byte) {
+	e.add(k, treeValue{kind: kindOther, text: fmt.Sprint(v)})
+}
+
+func (e *treeObjectEncoder) AddByteString(k string, v []byte) {
+	e.add(k, treeValue{kind: kindString, text: string(v)})
+}
+
+func (e *treeObjectEncoder) AddBool(k string, v bool) {
+	e.add(k, treeValue{kind: kindBool, text: strconv.FormatBool(v)})
+}
+
+func (e *treeObjectEncoder) AddComplex128(k string, v complex128) {
+	e.add(k, treeValue{kind: kindNumber, text: fmt.Sprint(v)})
+}
+
+func (e *treeObjectEncoder) AddComplex64(k string, v complex64) {
+	e.add(k, treeValue{kind: kindNumber, text: fmt.Sprint(v)})
+}
+
+func (e *treeObjectEncoder) AddDuration(k string, v time.Duration) {
+	e.add(k, treeValue{kind: kindDuration, text: encodeDurationString(e.encCfg, v)})
+}
+
+func (e *treeObjectEncoder) AddFloat64(k string, v float64) {
+	e.add(k, treeValue{kind: kindNumber, text: strconv.FormatFloat(v, 'f', -1, 64)})
+}
+
+func (e *treeObjectEncoder) AddFloat32(k string, v float32) {
+	e.add(k, treeValue{kind: kindNumber, text: strconv.FormatFloat(float64(v), 'f', -1, 32)})
+}
+
+func (e *treeObjectEncoder) AddInt(k string, v int) {
+	e.AddInt64(k, int64(v))
+}
+
+func (e *treeObjectEncoder) AddInt64(k string, v int64) {
+	e.add(k, treeValue{kind: kindNumber, text: strconv.FormatInt(v, 10)})
+}
+
+func (e *treeObjectEncoder) AddInt32(k string, v int32) {
+	e.AddInt64(k, int64(v))
+}
+
+func (e *treeObjectEncoder) AddInt16(k string, v int16) {
+	e.AddInt64(k, int64(v))
+}
+
+func (e *treeObjectEncoder) AddInt8(k string, v int8) {
+	e.AddInt64(k, int64(v))
+}
+
+func (e *treeObjectEncoder) AddString(k string, v string) {
+	e.add(k, treeValue{kind: kindString, text: v})
+}
+
+func (e *treeObjectEncoder) AddTime(k string, v time.Time) {
+	e.add(k, treeValue{kind: kindTime, text: encodeTimeString(e.encCfg, v)})
+}
+
+func (e *treeObjectEncoder) AddUint(k string, v uint) {
+	e.AddUint64(k, uint64(v))
+}
+
+func (e *treeObjectEncoder) AddUint64(k string, v uint64) {
+	e.add(k, treeValue{kind: kindNumber, text: strconv.FormatUint(v, 10)})
+}
+
+func (e *treeObjectEncoder) AddUint32(k string, v uint32) {
+	e.AddUint64(k, uint64(v))
+}
+
+func (e *treeObjectEncoder) AddUint16(k string, v uint16) {
+	e.AddUint64(k, uint64(v))
+}
+
+func (e *treeObjectEncoder) AddUint8(k string, v uint8) {
+	e.AddUint64(k, uint64(v))
+}
+
+func (e *treeObjectEncoder) AddUintptr(k string, v uintptr) {
+	e.AddUint64(k, uint64(v))
+}
+
+func (e *treeObjectEncoder) AddReflected(k string, v any) error {
+	e.add(k, reflectedTreeValue(v))
+	return nil
+}
+
+func (e *treeObjectEncoder) OpenNamespace(key string) {
+	e.add(key, treeValue{kind: kindObject})
+	e.ns = append(e.ns, key)
+}
+
+// treeArrayEncoder is a zapcore.ArrayEncoder collecting elements for a treeValue array.
+type treeArrayEncoder struct {
+	encCfg *zapcore.EncoderConfig
+	elems  []treeValue
+}
+
+func (e *treeArrayEncoder) append(v treeValue) {
+	e.elems = append(e.elems, v)
+}
+
+func (e *treeArrayEncoder) AppendBool(v bool) {
+	e.append(treeValue{kind: kindBool, text: strconv.FormatBool(v)})
+}
+
+func (e *treeArrayEncoder) AppendByteString(v []byte) {
+	e.append(treeValue{kind: kindString, text: string(v)})
+}
+
+func (e *treeArrayEncoder) AppendComplex128(v complex128) {
+	e.append(treeValue{kind: kindNumber, text: fmt.Sprint(v)})
+}
+
+func (e *treeArrayEncoder) AppendComplex64(v complex64) {
+	e.append(treeValue{kind: kindNumber, text: fmt.Sprint(v)})
+}
+
+func (e *treeArrayEncoder) AppendFloat64(v float64) {
+	e.append(treeValue{kind: kindNumber, text: strconv.FormatFloat(v, 'f', -1, 64)})
+}
+
+func (e *treeArrayEncoder) AppendFloat32(v float32) {
+	e.append(treeValue{kind: kindNumber, text: strconv.FormatFloat(float64(v), 'f', -1, 32)})
+}
+
+func (e *treeArrayEncoder) AppendInt(v int) {
+	e.AppendInt64(int64(v))
+}
+
+func (e *treeArrayEncoder) AppendInt64(v int64) {
+	e.append(treeValue{kind: kindNumber, text: strconv.FormatInt(v, 10)})
+}
+
+func (e *treeArrayEncoder) AppendInt32(v int32) {
+	e.AppendInt64(int64(v))
+}
+
+func (e *treeArrayEncoder) AppendInt16(v int16) {
+	e.AppendInt64(int64(v))
+}
+
+func (e *treeArrayEncoder) AppendInt8(v int8) {
+	e.AppendInt64(int64(v))
+}
+
+func (e *treeArrayEncoder) AppendString(v string) {
+	e.append(treeValue{kind: kindString, text: v})
+}
+
+func (e *treeArrayEncoder) AppendUint(v uint) {
+	e.AppendUint64(uint64(v))
+}
+
+func (e *treeArrayEncoder) AppendUint64(v uint64) {
+	e.append(treeValue{kind: kindNumber, text: strconv.FormatUint(v, 10)})
+}
+
+func (e *treeArrayEncoder) AppendUint32(v uint32) {
+	e.AppendUint64(uint64(v))
+}
+
+func (e *treeArrayEncoder) AppendUint16(v uint16) {
+	e.AppendUint64(uint64(v))
+}
+
+func (e *treeArrayEncoder) AppendUint8(v uint8) {
+	e.AppendUint64(uint64(v))
+}
+
+func (e *treeArrayEncoder) AppendUintptr(v uintptr) {
+	e.AppendUint64(uint64(v))
+}
+
+func (e *treeArrayEncoder) AppendDuration(v time.Duration) {
+	e.append(treeValue{kind: kindDuration, text: encodeDurationString(e.encCfg, v)})
+}
+
+func (e *treeArrayEncoder) AppendTime(v time.Time) {
+	e.append(treeValue{kind: kindTime, text: encodeTimeString(e.encCfg, v)})
+}
+
+func (e *treeArrayEncoder) AppendArray(v zapcore.ArrayMarshaler) error {
+	inner := &treeArrayEncoder{encCfg: e.encCfg}
+	err := v.MarshalLogArray(inner)
+	e.append(treeValue{kind: kindArray, elems: inner.elems})
+	return err
+}
+
+func (e *treeArrayEncoder) AppendObject(v zapcore.ObjectMarshaler) error {
+	obj := newTreeObjectEncoder(e.encCfg)
+	err := v.MarshalLogObject(obj)
+	e.append(treeValue{kind: kindObject, obj: obj.fields})
+	return err
+}
+
+func (e *treeArrayEncoder) AppendReflected(v any) error {
+	e.append(reflectedTreeValue(v))
+	return nil
+}
+
+// reflectedTreeValue converts an arbitrary value to a tree through its JSON encoding, the
+// form zap's JSON encoder logs it in, so maps, structs and slices nest like marshalers.
+// Values JSON cannot encode are rendered with fmt.Sprint.
+func reflectedTreeValue(v any) treeValue {
+	if v == nil {
+		return treeValue{kind: kindOther, text: "<nil>"}
+	}
+	if data, err := json.Marshal(v); err == nil {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if tv, err := decodeTreeValue(dec); err == nil {
+			return tv
+		}
+	}
+	return treeValue{kind: kindOther, text: fmt.Sprint(v)}
+}
+
+// decodeTreeValue reads the next JSON value from dec, keeping object keys in order.
+func decodeTreeValue(dec *json.Decoder) (treeValue, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return treeValue{}, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		v := treeValue{kind: kindArray}
+		if t == '{' {
+			v.kind = kindObject
+		}
+		for dec.More() {
+			if v.kind == kindObject {
+				key, err := dec.Token()
+				if err != nil {
+					return treeValue{}, err
+				}
+				val, err := decodeTreeValue(dec)
+				if err != nil {
+					return treeValue{}, err
+				}
+				v.obj = append(v.obj, treeField{key: key.(string), val: val})
+				continue
+			}
+			el, err := decodeTreeValue(dec)
+			if err != nil {
+				return treeValue{}, err
+			}
+			v.elems = append(v.elems, el)
+		}
+		_, err = dec.Token() // closing delimiter
+		return v, err
+	case string:
+		return treeValue{kind: kindString, text: t}, nil
+	case json.Number:
+		return treeValue{kind: kindNumber, text: t.String()}, nil
+	case bool:
+		return treeValue{kind: kindBool, text: strconv.FormatBool(t)}, nil
+	default:
+		return treeValue{kind: kindOther, text: "<nil>"}, nil
+	}
+}
+
+// encodeDurationString renders d with the configured duration encoder, falling back to
+// time.Duration.String.
+func encodeDurationString(encCfg *zapcore.EncoderConfig, d time.Duration) string {
+	if encCfg == nil || encCfg.EncodeDuration == nil {
+		return d.String()
+	}
+	enc := &singleValueEncoder{}
+	encCfg.EncodeDuration(d, enc)
+	return enc.val
+}
+
+// encodeTimeString renders t with the configured time encoder, falling back to time.Time.String.
+func encodeTimeString(encCfg *zapcore.EncoderConfig, t time.Time) string {
+	if encCfg == nil || encCfg.EncodeTime == nil {
+		return t.String()
+	}
+	enc := &singleValueEncoder{}
+	encCfg.EncodeTime(t, enc)
+	return enc.val
+}
+
+// inlineText renders v on a single line for the text formats, in the given NestedFormat.
+// Nested strings are sanitised (braces) or JSON-escaped (json), so they cannot break the line.
+func inlineText(v treeValue, format NestedFormat) string {
+	var b strings.Builder
+	if format == NestedJSON {
+		appendInlineJSON(&b, v)
+	} else {
+		appendInlineBraces(&b, v)
+	}
+	return b.String()
+}
+
+// appendInlineBraces writes v as {a=1, b={c=2}} and [x, y].
+func appendInlineBraces(b *strings.Builder, v treeValue) {
+	switch v.kind {
+	case kindObject:
+		b.WriteByte('{')
+		for i, f := range v.obj {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(sanitizeLogString(f.key))
+			b.WriteByte('=')
+			appendInlineBraces(b, f.val)
+		}
+		b.WriteByte('}')
+	case kindArray:
+		b.WriteByte('[')
+		for i, el := range v.elems {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			appendInlineBraces(b, el)
+		}
+		b.WriteByte(']')
+	default:
+		b.WriteString(sanitizeLogString(v.text))
+	}
+}
+
+// appendInlineJSON writes v as compact JSON. Numbers JSON cannot represent (NaN, Inf,
+// complex) and every non-bool scalar are written as strings.
+func appendInlineJSON(b *strings.Builder, v treeValue) {
+	switch v.kind {
+	case kindObject:
+		b.WriteByte('{')
+		for i, f := range v.obj {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			appendJSONString(b, f.key)
+			b.WriteByte(':')
+			appendInlineJSON(b, f.val)
+		}
+		b.WriteByte('}')
+	case kindArray:
+		b.WriteByte('[')
+		for i, el := range v.elems {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			appendInlineJSON(b, el)
+		}
+		b.WriteByte(']')
+	case kindBool:
+		b.WriteString(v.text)
+	case kindNumber:
+		if json.Valid([]byte(v.text)) {
+			b.WriteString(v.text)
+		} else {
+			appendJSONString(b, v.text)
+		}
+	default:
+		appendJSONString(b, v.text)
+	}
+}
+
+// appendJSONString writes s as a JSON string literal. HTML characters are left as is.
+func appendJSONString(b *strings.Builder, s string) {
+	const hex = "0123456789abcdef"
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20:
+			b.WriteString(`\u00`)
+			b.WriteByte(hex[r>>4])
+			b.WriteByte(hex[r&0xf])
+		default:
+			b.WriteRune(r) // invalid UTF-8 was already decoded to U+FFFD
+		}
+	}
+	b.WriteByte('"')
+}
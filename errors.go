@@ -0,0 +1,233 @@
+package dslogger
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// errorField is the field an error value is logged as. Structured encoders get
+//
+//	key:         err.Error()
+//	keyCauses:   messages of every wrapped error, depth first (errors.Join and %w)
+//	keyVerbose:  fmt's "%+v" rendering, when the error formats itself differently
+//	keyStack:    the stack recorded by the innermost error carrying one
+//
+// while the console formats get a compact key: message pair and print the stack
+// below the line, like an entry stack.
+type errorField struct {
+	key      string
+	err      error
+	maxDepth int // stack frame limit
+}
+
+// fieldFor returns the zap field for a key/value pair, non-nil errors become an errorField.
+// A typed nil error, such as a nil *MyErr, is logged as "<nil>" like zap.Error does.
+func (c *Config) fieldFor(key string, val any) zap.Field {
+	if err, ok := val.(error); ok && err != nil {
+		if isNilError(err) {
+			return zap.String(key, "<nil>")
+		}
+		return zap.Inline(errorField{key: key, err: err, maxDepth: c.stackDepth()})
+	}
+	return zap.Any(key, val)
+}
+
+// isNilError reports whether err is an interface holding a nil pointer, map, slice,
+// channel or function, whose Error method would likely panic.
+func isNilError(err error) bool {
+	switch v := reflect.ValueOf(err); v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// errorFields replaces every non-nil error value of the normalized key/value pairs with
+// an errorField. fields is returned as is when it holds no error.
+func (c *Config) errorFields(fields []any) []any {
+	if !hasErrorValue(fields) {
+		return fields
+	}
+
+	out := make([]any, 0, len(fields))
+	for i := 0; i < len(fields); i++ {
+		if _, ok := fields[i].(zap.Field); ok || i+1 == len(fields) {
+			out = append(out, fields[i])
+			continue
+		}
+		if err, ok := fields[i+1].(error); ok && err != nil {
+			key, ok := fields[i].(string)
+			if !ok {
+				key = fmt.Sprintf("%v", fields[i])
+			}
+			out = append(out, c.fieldFor(key, err))
+		} else {
+			out = append(out, fields[i], fields[i+1])
+		}
+		i++
+	}
+	return out
+}
+
+// hasErrorValue reports whether a key/value pair of fields holds a non-nil error. A
+// zap.Field takes a single slot, like in sweetenFields.
+func hasErrorValue(fields []any) bool {
+	for i := 0; i < len(fields); i++ {
+		if _, ok := fields[i].(zap.Field); ok {
+			continue
+		}
+		if i+1 < len(fields) {
+			if err, ok := fields[i+1].(error); ok && err != nil {
+				return true
+			}
+		}
+		i++
+	}
+	return false
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler.
+func (f errorField) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	switch enc.(type) {
+	case *dsConsoleEncoder, *dsPrettyEncoder, *treeObjectEncoder:
+		enc.AddString(f.key, compactError(f.err))
+		return nil
+	}
+
+	enc.AddString(f.key, f.err.Error())
+	if causes := errorCauses(f.err); len(causes) > 0 {
+		_ = enc.AddArray(f.key+"Causes", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+			for _, c := range causes {
+				arr.AppendString(c)
+			}
+			return nil
+		}))
+	}
+	if _, ok := f.err.(fmt.Formatter); ok {
+		if verbose := fmt.Sprintf("%+v", f.err); verbose != f.err.Error() {
+			enc.AddString(f.key+"Verbose", verbose)
+		}
+	}
+	if frames := f.stackFrames(); len(frames) > 0 {
+		enc.AddString(f.key+"Stack", formatFrames(frames))
+	}
+	return nil
+}
+
+func (f errorField) stackFrames() []runtime.Frame {
+	return errorStack(f.err, f.maxDepth)
+}
+
+// unwrapAll returns the errors directly wrapped by err, supporting both Unwrap forms.
+func unwrapAll(err error) []error {
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		if inner := u.Unwrap(); inner != nil {
+			return []error{inner}
+		}
+	case interface{ Unwrap() []error }:
+		return u.Unwrap()
+	}
+	return nil
+}
+
+// errorCauses lists the messages of every error wrapped by err, depth first.
+func errorCauses(err error) []string {
+	var causes []string
+	var walk func(error)
+	walk = func(e error) {
+		for _, inner := range unwrapAll(e) {
+			if inner == nil || isNilError(inner) {
+				continue
+			}
+			causes = append(causes, inner.Error())
+			walk(inner)
+		}
+	}
+	walk(err)
+	return causes
+}
+
+// compactError renders err on one line for the console formats. A %w chain already
+// reads as "outer: inner", joined errors are listed as [a; b] wherever they sit in it.
+func compactError(err error) string {
+	msg := err.Error()
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		// Only a join below can put a newline in the message
+		inner := u.Unwrap()
+		if inner == nil || isNilError(inner) || !strings.Contains(msg, "\n") {
+			break
+		}
+		if prefix, ok := strings.CutSuffix(msg, inner.Error()); ok {
+			return prefix + compactError(inner)
+		}
+	case interface{ Unwrap() []error }:
+		inner := u.Unwrap()
+		msgs := make([]string, 0, len(inner))
+		for _, e := range inner {
+			if e != nil && !isNilError(e) {
+				msgs = append(msgs, e.Error())
+			}
+		}
+		if strings.Join(msgs, "\n") == msg {
+			parts := make([]string, 0, len(inner))
+			for _, e := range inner {
+				if e != nil && !isNilError(e) {
+					parts = append(parts, compactError(e))
+				}
+			}
+			return "[" + strings.Join(parts, "; ") + "]"
+		}
+	}
+	return msg
+}
+
+// errorStack returns the trimmed stack recorded by the innermost error of the chain
+// that carries one, or nil when none does.
+// Errors carry a stack by implementing Callers() []uintptr, or a StackTrace method
+// returning a slice of program counters such as github.com/pkg/errors' StackTrace.
+func errorStack(err error, maxDepth int) []runtime.Frame {
+	var pcs []uintptr
+	var walk func(error)
+	walk = func(e error) {
+		if p := stackPCs(e); len(p) > 0 {
+			pcs = p
+		}
+		for _, inner := range unwrapAll(e) {
+			if inner != nil && !isNilError(inner) {
+				walk(inner)
+			}
+		}
+	}
+	walk(err)
+	if len(pcs) == 0 {
+		return nil
+	}
+	return trimFrames(pcs, maxDepth)
+}
+
+// stackPCs extracts the program counters recorded by err, if any.
+func stackPCs(err error) []uintptr {
+	if c, ok := err.(interface{ Callers() []uintptr }); ok {
+		return c.Callers()
+	}
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+	out := m.Call(nil)[0]
+	if out.Kind() != reflect.Slice || out.Type().Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	pcs := make([]uintptr, out.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(out.Index(i).Uint())
+	}
+	return pcs
+}
@@ -0,0 +1,209 @@
+package dslogger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// stackError records the stack where it was created, like github.com/pkg/errors.
+type stackError struct {
+	msg string
+	pcs []uintptr
+}
+
+func newStackError(msg string) *stackError {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(2, pcs)
+	return &stackError{msg: msg, pcs: pcs[:n]}
+}
+
+func (e *stackError) Error() string      { return e.msg }
+func (e *stackError) Callers() []uintptr { return e.pcs }
+func (e *stackError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		fmt.Fprintf(s, "%s (verbose)", e.msg)
+		return
+	}
+	io.WriteString(s, e.msg)
+}
+
+// errorTestLogger returns a console logger writing to buf in the given format.
+func errorTestLogger(t *testing.T, buf *bytes.Buffer, format LogFormat) *Logger {
+	t.Helper()
+	cfg := NewDefaultConfig()
+	cfg.ConsoleWriter = buf
+	cfg.ConsoleFormat = format
+	cfg.NoColor = true
+	cfg.ConsoleConfig.CallerKey = "caller"
+	logger, err := NewConsoleLogger("info", &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+// TestErrorFieldJSON checks causes, verbose and stack for a joined, wrapped error.
+func TestErrorFieldJSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger := errorTestLogger(t, &buf, LogFormatJSON)
+
+	err := errors.Join(fmt.Errorf("read config: %w", io.EOF), newStackError("dial failed"))
+	logger.Error("startup failed", "err", err)
+	_ = logger.Sync()
+
+	var entry map[string]any
+	if jerr := json.Unmarshal(buf.Bytes(), &entry); jerr != nil {
+		t.Fatalf("invalid JSON: %v\n%s", jerr, buf.String())
+	}
+	if entry["err"] != "read config: EOF\ndial failed" {
+		t.Errorf("err = %q", entry["err"])
+	}
+	causes, _ := entry["errCauses"].([]any)
+	want := []any{"read config: EOF", "EOF", "dial failed"}
+	if fmt.Sprint(causes) != fmt.Sprint(want) {
+		t.Errorf("errCauses = %v, want %v", causes, want)
+	}
+	if _, ok := entry["errVerbose"]; ok {
+		t.Errorf("errVerbose set for an error without a verbose format: %v", entry)
+	}
+	if st, _ := entry["errStack"].(string); !strings.Contains(st, "TestErrorFieldJSON") {
+		t.Errorf("errStack does not point at the creating function: %q", st)
+	}
+
+	buf.Reset()
+	logger.Error("dial", "err", newStackError("dial failed"))
+	_ = logger.Sync()
+	entry = nil
+	if jerr := json.Unmarshal(buf.Bytes(), &entry); jerr != nil {
+		t.Fatalf("invalid JSON: %v\n%s", jerr, buf.String())
+	}
+	if entry["errVerbose"] != "dial failed (verbose)" {
+		t.Errorf("errVerbose = %q", entry["errVerbose"])
+	}
+}
+
+// TestErrorFieldConsole checks the compact chain and the stack printed below the line.
+func TestErrorFieldConsole(t *testing.T) {
+	var buf bytes.Buffer
+	logger := errorTestLogger(t, &buf, LogFormatText)
+
+	err := errors.Join(fmt.Errorf("read config: %w", io.EOF), newStackError("dial failed"))
+	logger.Error("startup failed", "err", err, "attempt", 2)
+	_ = logger.Sync()
+
+	first, rest, _ := strings.Cut(buf.String(), "\n")
+	if !strings.HasSuffix(first, "startup failed | err: [read config: EOF; dial failed] | attempt: 2") {
+		t.Errorf("unexpected line: %q", first)
+	}
+	if !strings.Contains(rest, "TestErrorFieldConsole") {
+		t.Errorf("error stack missing below the line: %q", rest)
+	}
+}
+
+// TestErrorFieldConsoleWrappedJoin checks a join wrapped with %w is listed in place.
+func TestErrorFieldConsoleWrappedJoin(t *testing.T) {
+	var buf bytes.Buffer
+	logger := errorTestLogger(t, &buf, LogFormatText)
+
+	join := errors.Join(io.EOF, fmt.Errorf("retry: %w", errors.Join(io.ErrClosedPipe, io.ErrShortWrite)))
+	logger.Error("sync failed", "err", fmt.Errorf("outer: %w", fmt.Errorf("middle: %w", join)))
+	_ = logger.Sync()
+
+	want := "sync failed | err: outer: middle: [EOF; retry: [io: read/write on closed pipe; short write]]\n"
+	if got := buf.String(); !strings.HasSuffix(got, want) {
+		t.Errorf("unexpected line: %q", got)
+	}
+}
+
+// TestErrorFieldWithFields verifies errors attached through WithFields.
+func TestErrorFieldWithFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := errorTestLogger(t, &buf, LogFormatText)
+	logger.WithFields("cause", fmt.Errorf("wrap: %w", io.ErrUnexpectedEOF)).Warn("degraded")
+	_ = logger.Sync()
+	if !strings.Contains(buf.String(), "degraded | cause: wrap: unexpected EOF") {
+		t.Errorf("unexpected output: %q", buf.String())
+	}
+}
+
+// TestLoggerErr checks level, key, caller and extra fields of Logger.Err.
+func TestLoggerErr(t *testing.T) {
+	var buf bytes.Buffer
+	logger := errorTestLogger(t, &buf, LogFormatJSON)
+	logger.Err(fmt.Errorf("query: %w", io.EOF), "request failed", "user", "u-1")
+	_ = logger.Sync()
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if entry["level"] != "ERROR" || entry["message"] != "request failed" || entry["user"] != "u-1" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+	if entry["error"] != "query: EOF" || fmt.Sprint(entry["errorCauses"]) != "[EOF]" {
+		t.Errorf("unexpected error fields: %v", entry)
+	}
+	if caller, _ := entry["caller"].(string); !strings.Contains(caller, "errors_test.go") {
+		t.Errorf("caller = %q, want errors_test.go", caller)
+	}
+}
+
+// TestTypedNilError checks a nil pointer held by an error interface is logged as "<nil>"
+// instead of panicking, as a field and through Logger.Err.
+func TestTypedNilError(t *testing.T) {
+	var buf bytes.Buffer
+	logger := errorTestLogger(t, &buf, LogFormatJSON)
+	var e *stackError
+	logger.Error("lookup failed", "error", e)
+	logger.Err(e, "lookup failed")
+	_ = logger.Sync()
+
+	dec := json.NewDecoder(&buf)
+	for _, want := range []string{"<nil>", "<nil>"} {
+		var entry map[string]any
+		if err := dec.Decode(&entry); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if got, _ := entry["error"].(string); got != want {
+			t.Errorf("error = %q, want %q: %v", got, want, entry)
+		}
+	}
+}
+
+// TestErrorFieldAfterZapField checks a zap.Field takes a single slot, so the pairs after
+// it keep their alignment and errors their rich rendering.
+func TestErrorFieldAfterZapField(t *testing.T) {
+	var buf bytes.Buffer
+	logger := errorTestLogger(t, &buf, LogFormatJSON)
+	err := fmt.Errorf("query: %w", io.EOF)
+	logger.Error("request failed", zap.Int("attempt", 2), "error", err, "user")
+	logger.WithFields(zap.Bool("retry", true), "cause", err).Warn("degraded")
+	_ = logger.Sync()
+
+	dec := json.NewDecoder(&buf)
+	for _, want := range []map[string]any{
+		{"attempt": float64(2), "error": "query: EOF", "user": "<missing>"},
+		{"retry": true, "cause": "query: EOF"},
+	} {
+		var entry map[string]any
+		if err := dec.Decode(&entry); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		for k, v := range want {
+			if entry[k] != v {
+				t.Errorf("%s = %v, want %v: %v", k, entry[k], v, entry)
+			}
+		}
+		if entry["errorCauses"] == nil && entry["causeCauses"] == nil {
+			t.Errorf("error not rendered by errorField: %v", entry)
+		}
+	}
+}
@@ -1,6 +1,11 @@
 package dslogger
 
-import "strings"
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/width"
+)
 
 // sanitizeLogString replaces control characters (\r, \n, \x00) with escape sequences
 // to prevent log injection attacks on the console path.
@@ -25,3 +30,66 @@ func sanitizeLogString(s string) string {
 	}
 	return b.String()
 }
+
+// ellipsis marks the truncated end of a fixed-width column.
+const ellipsis = "…"
+
+// runeWidth returns the number of terminal cells r takes: 2 for East Asian wide and
+// fullwidth characters, 0 for combining marks, 1 otherwise.
+func runeWidth(r rune) int {
+	if unicode.In(r, unicode.Mn, unicode.Me) {
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// displayWidth returns the number of terminal cells s takes.
+func displayWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		n += runeWidth(r)
+	}
+	return n
+}
+
+// truncateLeft shortens s to at most width cells, replacing its head with an ellipsis
+// so the most specific part (file name, line) stays visible. width <= 0 disables it.
+func truncateLeft(s string, width int) string {
+	if width <= 0 || displayWidth(s) <= width {
+		return s
+	}
+	r := []rune(s)
+	i, n := len(r), 0
+	for i > 0 && n+runeWidth(r[i-1]) <= width-1 {
+		i--
+		n += runeWidth(r[i])
+	}
+	return ellipsis + string(r[i:])
+}
+
+// truncateRight shortens s to at most width cells, replacing its tail with an ellipsis.
+// width <= 0 disables it.
+func truncateRight(s string, width int) string {
+	if width <= 0 || displayWidth(s) <= width {
+		return s
+	}
+	r := []rune(s)
+	i, n := 0, 0
+	for i < len(r) && n+runeWidth(r[i]) <= width-1 {
+		n += runeWidth(r[i])
+		i++
+	}
+	return string(r[:i]) + ellipsis
+}
+
+// padding returns the spaces needed to extend s to width cells.
+func padding(s string, width int) string {
+	if n := width - displayWidth(s); n > 0 {
+		return strings.Repeat(" ", n)
+	}
+	return ""
+}
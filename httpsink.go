@@ -0,0 +1,500 @@
+package dslogger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// HTTPEntry is one encoded log entry waiting in an HTTP batch.
+type HTTPEntry struct {
+	Time  time.Time
+	Level zapcore.Level
+	Line  []byte // encoded entry, including the trailing newline
+}
+
+// HTTPBodyFormatter turns a batch into a request body and its Content-Type.
+type HTTPBodyFormatter func(batch []HTTPEntry) (body []byte, contentType string, err error)
+
+// HTTPConfig holds configuration options for an HTTPSink.
+type HTTPConfig struct {
+	// URL is the endpoint batches are sent to.
+	URL string
+
+	// Method defaults to POST.
+	Method string
+
+	// Headers are added to every request (authentication, tenant IDs...).
+	Headers http.Header
+
+	// Client defaults to an http.Client with a 10s timeout.
+	Client *http.Client
+
+	// Formatter builds the request body, defaults to NDJSONFormatter.
+	Formatter HTTPBodyFormatter
+
+	// EncoderConfig is used to encode each entry as JSON, defaults to DefaultJSONEncoderConfig.
+	EncoderConfig zapcore.EncoderConfig
+
+	// A batch is sent when it holds BatchSize entries (default 100), reaches BatchBytes
+	// of encoded entries (default 1 MiB), or FlushInterval (default 1s) has elapsed.
+	BatchSize     int
+	BatchBytes    int
+	FlushInterval time.Duration
+
+	// QueueSize is the number of full batches allowed to wait for delivery (default 8).
+	// When the queue is full the oldest pending batch is kept and the new one is dropped,
+	// so a slow backend never blocks log calls.
+	QueueSize int
+
+	// DisableGzip sends uncompressed bodies. By default bodies are gzipped and sent
+	// with Content-Encoding: gzip.
+	DisableGzip bool
+
+	// MaxRetries is the number of retries after a 429, a 5xx or a transport error.
+	// Zero means the default of 3, use -1 to disable retries. Retry-After headers are
+	// honoured up to MaxBackoff.
+	MaxRetries int
+	MinBackoff time.Duration // first retry delay, defaults to 100ms
+	MaxBackoff time.Duration // retry delay cap, defaults to 30s
+
+	// ErrorHandler receives delivery failures of batches sent in the background.
+	// Defaults to printing the error to stderr. Failures of batches flushed by
+	// Sync or Close are returned to the caller instead.
+	ErrorHandler func(error)
+
+	// Level filters entries on top of the logger's own level. A nil value accepts every entry.
+	Level zapcore.LevelEnabler
+}
+
+// applyHTTPDefaults fills the zero-valued fields of cfg in place.
+func applyHTTPDefaults(cfg *HTTPConfig) {
+	if cfg.Method == "" {
+		cfg.Method = http.MethodPost
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if cfg.Formatter == nil {
+		cfg.Formatter = NDJSONFormatter
+	}
+	if cfg.EncoderConfig.EncodeTime == nil {
+		cfg.EncoderConfig = DefaultJSONEncoderConfig
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.BatchBytes <= 0 {
+		cfg.BatchBytes = 1 << 20
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 8
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 3
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = defaultMinBackoff
+	}
+	if cfg.MaxBackoff < cfg.MinBackoff {
+		cfg.MaxBackoff = max(defaultMaxBackoff, cfg.MinBackoff)
+	}
+	if cfg.ErrorHandler == nil {
+		cfg.ErrorHandler = func(err error) {
+			fmt.Fprintf(os.Stderr, "dslogger: http sink: %v\n", err)
+		}
+	}
+	if cfg.Level == nil {
+		cfg.Level = zapcore.DebugLevel
+	}
+}
+
+// HTTPSink is a zapcore.Core that encodes entries as JSON and ships them in batches
+// over HTTP, for backends such as Loki, Elasticsearch or generic webhooks.
+// Attach it with WithSink, Logger.Sync flushes the pending batch and Logger.Close
+// flushes and stops the background sender.
+type HTTPSink struct {
+	enc     zapcore.Encoder
+	batcher *httpBatcher
+}
+
+// NewHTTPSink creates an HTTPSink and starts its background sender.
+func NewHTTPSink(cfg HTTPConfig) (*HTTPSink, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("dslogger: HTTPConfig.URL is required")
+	}
+	applyHTTPDefaults(&cfg)
+
+	b := &httpBatcher{
+		cfg:      &cfg,
+		queue:    make(chan []HTTPEntry, cfg.QueueSize),
+		flushReq: make(chan chan error),
+		closing:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	b.wg.Add(1)
+	go b.run()
+
+	return &HTTPSink{enc: zapcore.NewJSONEncoder(cfg.EncoderConfig), batcher: b}, nil
+}
+
+// Enabled reports whether the sink accepts entries at the given level.
+func (s *HTTPSink) Enabled(lvl zapcore.Level) bool {
+	return s.batcher.cfg.Level.Enabled(lvl)
+}
+
+// With returns a copy of the sink carrying the given context fields.
+func (s *HTTPSink) With(fields []zapcore.Field) zapcore.Core {
+	enc := s.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return &HTTPSink{enc: enc, batcher: s.batcher}
+}
+
+// Check adds the sink to the checked entry if the level is enabled.
+func (s *HTTPSink) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if s.Enabled(ent.Level) {
+		return ce.AddCore(ent, s)
+	}
+	return ce
+}
+
+// Write encodes the entry and adds it to the current batch.
+func (s *HTTPSink) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := s.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	line := slices.Clone(buf.Bytes())
+	buf.Free()
+	return s.batcher.add(HTTPEntry{Time: ent.Time, Level: ent.Level, Line: line})
+}
+
+// Sync sends the pending batch and waits for every queued batch to be delivered.
+func (s *HTTPSink) Sync() error {
+	return s.batcher.flush()
+}
+
+// Close flushes pending entries and stops the background sender.
+// Logger.Close calls it for sinks attached via WithSink.
+func (s *HTTPSink) Close() error {
+	return s.batcher.close()
+}
+
+// Dropped returns the number of entries dropped because the delivery queue was full
+// or the sink was closed.
+func (s *HTTPSink) Dropped() uint64 {
+	return s.batcher.dropped.Load()
+}
+
+// httpBatcher accumulates entries and delivers batches from a single goroutine,
+// so requests to the backend are never concurrent and batches stay in order.
+type httpBatcher struct {
+	cfg *HTTPConfig
+
+	mu           sync.Mutex
+	pending      []HTTPEntry
+	pendingBytes int
+	closed       bool
+
+	queue    chan []HTTPEntry
+	flushReq chan chan error
+	closing  chan struct{} // closed by close, cuts short the retry waits
+	done     chan struct{}
+	wg       sync.WaitGroup
+	dropped  atomic.Uint64
+}
+
+// add appends e to the pending batch and hands the batch to the sender once full.
+// The batch is queued under b.mu, so close cannot drain the queue in between and lose it.
+func (b *httpBatcher) add(e HTTPEntry) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		b.dropped.Add(1)
+		return errors.New("dslogger: http sink is closed")
+	}
+	b.pending = append(b.pending, e)
+	b.pendingBytes += len(e.Line)
+	if len(b.pending) < b.cfg.BatchSize && b.pendingBytes < b.cfg.BatchBytes {
+		return nil
+	}
+	full := b.takeLocked()
+	select {
+	case b.queue <- full:
+		return nil
+	default:
+		b.dropped.Add(uint64(len(full)))
+		return fmt.Errorf("dslogger: http sink queue full, dropped %d entries", len(full))
+	}
+}
+
+// takeLocked detaches the pending batch. The caller must hold b.mu.
+func (b *httpBatcher) takeLocked() []HTTPEntry {
+	batch := b.pending
+	b.pending = nil
+	b.pendingBytes = 0
+	return batch
+}
+
+// takeAll detaches the queued batches followed by the pending one, oldest first. Holding
+// b.mu keeps add from queuing a batch in between. Only the sender may call it.
+func (b *httpBatcher) takeAll() [][]HTTPEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var batches [][]HTTPEntry
+	for len(b.queue) > 0 {
+		batches = append(batches, <-b.queue)
+	}
+	if batch := b.takeLocked(); len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// run is the sender loop.
+func (b *httpBatcher) run() {
+	defer b.wg.Done()
+	ticker := time.NewTicker(b.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case batch := <-b.queue:
+			if err := b.send(batch); err != nil {
+				b.cfg.ErrorHandler(err)
+			}
+		case <-ticker.C:
+			// Queued batches go first, as select may pick the tick over a ready queue
+			for _, batch := range b.takeAll() {
+				if err := b.send(batch); err != nil {
+					b.cfg.ErrorHandler(err)
+				}
+			}
+		case ack := <-b.flushReq:
+			ack <- b.drain()
+		case <-b.done:
+			return
+		}
+	}
+}
+
+// drain sends every queued batch followed by the pending one, joining the errors.
+func (b *httpBatcher) drain() error {
+	var errs []error
+	for _, batch := range b.takeAll() {
+		errs = append(errs, b.send(batch))
+	}
+	return errors.Join(errs...)
+}
+
+// flush asks the sender to drain and waits for the result.
+func (b *httpBatcher) flush() error {
+	ack := make(chan error, 1)
+	select {
+	case b.flushReq <- ack:
+		return <-ack
+	case <-b.done:
+		return nil
+	}
+}
+
+// close flushes, then stops the sender. Batches are not retried once close is called.
+// Subsequent calls are no-ops.
+func (b *httpBatcher) close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	close(b.closing)
+	b.mu.Unlock()
+
+	err := b.flush()
+	close(b.done)
+	b.wg.Wait()
+	return err
+}
+
+// send delivers one batch, retrying 429, 5xx and transport errors with exponential backoff
+// until the sink is closed.
+func (b *httpBatcher) send(batch []HTTPEntry) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	body, contentType, err := b.cfg.Formatter(batch)
+	if err != nil {
+		return fmt.Errorf("format batch: %w", err)
+	}
+	if !b.cfg.DisableGzip {
+		var zbuf bytes.Buffer
+		zw := gzip.NewWriter(&zbuf)
+		if _, err := zw.Write(body); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		body = zbuf.Bytes()
+	}
+
+	backoff := b.cfg.MinBackoff
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := b.post(body, contentType)
+		if err == nil {
+			return nil
+		}
+		var perm *httpPermanentError
+		if errors.As(err, &perm) || attempt >= b.cfg.MaxRetries {
+			return fmt.Errorf("send batch of %d entries: %w", len(batch), err)
+		}
+		wait := backoff
+		if retryAfter > 0 {
+			wait = retryAfter
+		}
+		timer := time.NewTimer(min(wait, b.cfg.MaxBackoff))
+		select {
+		case <-timer.C:
+		case <-b.closing:
+			timer.Stop()
+			return fmt.Errorf("send batch of %d entries: sink closed before retry: %w", len(batch), err)
+		}
+		backoff = min(backoff*2, b.cfg.MaxBackoff)
+	}
+}
+
+// httpPermanentError marks a failure that must not be retried, such as a 4xx other than 429.
+type httpPermanentError struct {
+	err error
+}
+
+func (e *httpPermanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *httpPermanentError) Unwrap() error {
+	return e.err
+}
+
+// post performs a single request and returns the server's Retry-After delay, if any.
+func (b *httpBatcher) post(body []byte, contentType string) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(context.Background(), b.cfg.Method, b.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, &httpPermanentError{err: err}
+	}
+	for k, vs := range b.cfg.Headers {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	req.Header.Set("Content-Type", contentType)
+	if !b.cfg.DisableGzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := b.cfg.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return 0, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		var retryAfter time.Duration
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			retryAfter = time.Duration(secs) * time.Second
+		}
+		return retryAfter, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	default:
+		return 0, &httpPermanentError{err: fmt.Errorf("unexpected status %d", resp.StatusCode)}
+	}
+}
+
+// NDJSONFormatter sends the batch as newline-delimited JSON, one entry per line.
+func NDJSONFormatter(batch []HTTPEntry) ([]byte, string, error) {
+	var buf bytes.Buffer
+	for _, e := range batch {
+		buf.Write(e.Line)
+	}
+	return buf.Bytes(), "application/x-ndjson", nil
+}
+
+// JSONArrayFormatter sends the batch as a single JSON array of entries.
+func JSONArrayFormatter(batch []HTTPEntry) ([]byte, string, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, e := range batch {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(bytes.TrimRight(e.Line, "\n"))
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), "application/json", nil
+}
+
+// ElasticsearchBulkFormatter returns a formatter producing an Elasticsearch _bulk body
+// that indexes every entry into index.
+func ElasticsearchBulkFormatter(index string) HTTPBodyFormatter {
+	action, _ := json.Marshal(map[string]map[string]string{"index": {"_index": index}})
+	action = append(action, '\n')
+	return func(batch []HTTPEntry) ([]byte, string, error) {
+		var buf bytes.Buffer
+		for _, e := range batch {
+			buf.Write(action)
+			buf.Write(e.Line)
+		}
+		return buf.Bytes(), "application/x-ndjson", nil
+	}
+}
+
+// LokiFormatter returns a formatter producing a Loki push API body (/loki/api/v1/push).
+// Entries are grouped into one stream per level, labelled with labels plus "level".
+func LokiFormatter(labels map[string]string) HTTPBodyFormatter {
+	type stream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+	return func(batch []HTTPEntry) ([]byte, string, error) {
+		var streams []*stream
+		byLevel := make(map[zapcore.Level]*stream)
+		for _, e := range batch {
+			s, ok := byLevel[e.Level]
+			if !ok {
+				l := make(map[string]string, len(labels)+1)
+				for k, v := range labels {
+					l[k] = v
+				}
+				l["level"] = e.Level.String()
+				s = &stream{Stream: l}
+				byLevel[e.Level] = s
+				streams = append(streams, s)
+			}
+			ts := strconv.FormatInt(e.Time.UnixNano(), 10)
+			s.Values = append(s.Values, [2]string{ts, string(bytes.TrimRight(e.Line, "\n"))})
+		}
+		body, err := json.Marshal(map[string][]*stream{"streams": streams})
+		return body, "application/json", err
+	}
+}
@@ -0,0 +1,370 @@
+package dslogger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// batchRecorder is an httptest handler recording every decoded request body.
+type batchRecorder struct {
+	mu      sync.Mutex
+	bodies  []string
+	headers []http.Header
+	status  func(n int) int // status for the n-th request (0-based), nil means 200
+	calls   atomic.Int32
+}
+
+func (r *batchRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	n := int(r.calls.Add(1)) - 1
+	var body io.Reader = req.Body
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		body = zr
+	}
+	data, _ := io.ReadAll(body)
+
+	status := http.StatusOK
+	if r.status != nil {
+		status = r.status(n)
+	}
+	if status == http.StatusOK {
+		r.mu.Lock()
+		r.bodies = append(r.bodies, string(data))
+		r.headers = append(r.headers, req.Header.Clone())
+		r.mu.Unlock()
+	}
+	w.WriteHeader(status)
+}
+
+func (r *batchRecorder) batches() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.bodies...)
+}
+
+// newHTTPSinkLogger returns a console-quiet logger shipping to an httptest server.
+func newHTTPSinkLogger(t *testing.T, rec *batchRecorder, cfg HTTPConfig) (*Logger, *HTTPSink) {
+	t.Helper()
+	srv := httptest.NewServer(rec)
+	t.Cleanup(srv.Close)
+
+	cfg.URL = srv.URL
+	if cfg.FlushInterval == 0 {
+		cfg.FlushInterval = time.Hour
+	}
+	cfg.MinBackoff = time.Millisecond
+	sink, err := NewHTTPSink(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := NewConsoleLogger("info", quietConsoleConfig(), WithSink(sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger, sink
+}
+
+// TestHTTPSinkBatchByCount sends a gzipped NDJSON batch once BatchSize is reached.
+func TestHTTPSinkBatchByCount(t *testing.T) {
+	rec := &batchRecorder{}
+	logger, _ := newHTTPSinkLogger(t, rec, HTTPConfig{
+		BatchSize: 3,
+		Headers:   http.Header{"X-Scope-OrgID": {"tenant-1"}},
+	})
+	defer logger.Close()
+
+	logger.Info("one", "n", 1)
+	logger.Info("two", "n", 2)
+	if len(rec.batches()) != 0 {
+		t.Fatal("batch sent before BatchSize was reached")
+	}
+	logger.Info("three", "n", 3)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(rec.batches()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	batches := rec.batches()
+	if len(batches) != 1 {
+		t.Fatalf("batches = %d, want 1", len(batches))
+	}
+	lines := strings.Split(strings.TrimRight(batches[0], "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("lines = %d, want 3: %q", len(lines), batches[0])
+	}
+	var parsed map[string]any
+	if err := json.Unmarshal([]byte(lines[2]), &parsed); err != nil || parsed["message"] != "three" {
+		t.Errorf("unexpected entry %q (err %v)", lines[2], err)
+	}
+	h := rec.headers[0]
+	if h.Get("Content-Encoding") != "gzip" || h.Get("Content-Type") != "application/x-ndjson" || h.Get("X-Scope-OrgID") != "tenant-1" {
+		t.Errorf("unexpected headers: %v", h)
+	}
+}
+
+// TestHTTPSinkSyncAndCloseFlush verifies Logger.Sync and Logger.Close deliver pending entries.
+func TestHTTPSinkSyncAndCloseFlush(t *testing.T) {
+	rec := &batchRecorder{}
+	logger, _ := newHTTPSinkLogger(t, rec, HTTPConfig{DisableGzip: true})
+
+	logger.Info("pending")
+	if err := logger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if b := rec.batches(); len(b) != 1 || !strings.Contains(b[0], "pending") {
+		t.Fatalf("Sync did not flush: %q", b)
+	}
+
+	logger.Info("at close")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if b := rec.batches(); len(b) != 2 || !strings.Contains(b[1], "at close") {
+		t.Fatalf("Close did not flush: %q", b)
+	}
+}
+
+// TestHTTPSinkFlushInterval verifies partial batches are sent on the interval.
+func TestHTTPSinkFlushInterval(t *testing.T) {
+	rec := &batchRecorder{}
+	logger, _ := newHTTPSinkLogger(t, rec, HTTPConfig{FlushInterval: 10 * time.Millisecond})
+	defer logger.Close()
+
+	logger.Info("tick")
+	deadline := time.Now().Add(2 * time.Second)
+	for len(rec.batches()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if len(rec.batches()) != 1 {
+		t.Error("interval flush did not send the pending batch")
+	}
+}
+
+// TestHTTPSinkFlushIntervalOrder verifies a tick sends the queued batches before the
+// pending one when both are ready.
+func TestHTTPSinkFlushIntervalOrder(t *testing.T) {
+	for range 10 {
+		release := make(chan struct{})
+		rec := &batchRecorder{status: func(n int) int {
+			if n == 0 {
+				<-release
+			}
+			return http.StatusOK
+		}}
+		logger, _ := newHTTPSinkLogger(t, rec, HTTPConfig{
+			BatchSize:     2,
+			FlushInterval: 5 * time.Millisecond,
+			DisableGzip:   true,
+		})
+
+		// The first batch holds the sender while the second is queued and the third pending
+		logger.Info("e1")
+		logger.Info("e2")
+		for rec.calls.Load() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		logger.Info("e3")
+		logger.Info("e4")
+		logger.Info("e5")
+		time.Sleep(20 * time.Millisecond) // let a tick become ready
+		close(release)
+		if err := logger.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		var got []string
+		for _, batch := range rec.batches() {
+			for _, line := range strings.Split(strings.TrimRight(batch, "\n"), "\n") {
+				var entry map[string]any
+				if err := json.Unmarshal([]byte(line), &entry); err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, entry["message"].(string))
+			}
+		}
+		if want := []string{"e1", "e2", "e3", "e4", "e5"}; !slices.Equal(got, want) {
+			t.Fatalf("entries sent out of order: %v", got)
+		}
+	}
+}
+
+// TestHTTPSinkRetries verifies 429 and 5xx responses are retried.
+func TestHTTPSinkRetries(t *testing.T) {
+	rec := &batchRecorder{status: func(n int) int {
+		switch n {
+		case 0:
+			return http.StatusTooManyRequests
+		case 1:
+			return http.StatusServiceUnavailable
+		default:
+			return http.StatusOK
+		}
+	}}
+	logger, _ := newHTTPSinkLogger(t, rec, HTTPConfig{})
+	defer logger.Close()
+
+	logger.Info("eventually delivered")
+	if err := logger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.calls.Load(); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+	if len(rec.batches()) != 1 {
+		t.Error("batch not delivered after retries")
+	}
+}
+
+// TestHTTPSinkNoRetries verifies a negative MaxRetries disables retries.
+func TestHTTPSinkNoRetries(t *testing.T) {
+	rec := &batchRecorder{status: func(int) int { return http.StatusServiceUnavailable }}
+	logger, _ := newHTTPSinkLogger(t, rec, HTTPConfig{MaxRetries: -1})
+	defer logger.Close()
+
+	logger.Info("not retried")
+	if err := logger.Sync(); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Sync error = %v, want status 503", err)
+	}
+	if got := rec.calls.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+// TestHTTPSinkPermanentError verifies 4xx responses are not retried and surface from Sync.
+func TestHTTPSinkPermanentError(t *testing.T) {
+	rec := &batchRecorder{status: func(int) int { return http.StatusBadRequest }}
+	logger, _ := newHTTPSinkLogger(t, rec, HTTPConfig{})
+	defer logger.Close()
+
+	logger.Info("rejected")
+	if err := logger.Sync(); err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("Sync error = %v, want status 400", err)
+	}
+	if got := rec.calls.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+// TestHTTPSinkFormatters checks the built-in body formatters.
+func TestHTTPSinkFormatters(t *testing.T) {
+	ts := time.Unix(1700000000, 5)
+	batch := []HTTPEntry{
+		{Time: ts, Level: zapcore.InfoLevel, Line: []byte(`{"message":"a"}` + "\n")},
+		{Time: ts, Level: zapcore.ErrorLevel, Line: []byte(`{"message":"b"}` + "\n")},
+	}
+
+	body, ct, _ := JSONArrayFormatter(batch)
+	if string(body) != `[{"message":"a"},{"message":"b"}]` || ct != "application/json" {
+		t.Errorf("JSONArrayFormatter = %s (%s)", body, ct)
+	}
+
+	body, _, _ = ElasticsearchBulkFormatter("logs")(batch)
+	wantBulk := `{"index":{"_index":"logs"}}` + "\n" + `{"message":"a"}` + "\n" +
+		`{"index":{"_index":"logs"}}` + "\n" + `{"message":"b"}` + "\n"
+	if string(body) != wantBulk {
+		t.Errorf("ElasticsearchBulkFormatter = %q", body)
+	}
+
+	body, _, _ = LokiFormatter(map[string]string{"app": "api"})(batch)
+	var push struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(body, &push); err != nil {
+		t.Fatal(err)
+	}
+	if len(push.Streams) != 2 {
+		t.Fatalf("streams = %d, want one per level", len(push.Streams))
+	}
+	s := push.Streams[0]
+	if s.Stream["app"] != "api" || s.Stream["level"] != "info" {
+		t.Errorf("labels = %v", s.Stream)
+	}
+	if s.Values[0][0] != "1700000000000000005" || s.Values[0][1] != `{"message":"a"}` {
+		t.Errorf("values = %v", s.Values)
+	}
+	if !bytes.Contains(body, []byte(`"level":"error"`)) {
+		t.Errorf("error stream missing: %s", body)
+	}
+}
+
+// TestHTTPSinkCloseDuringRetry verifies Close does not wait for a pending retry delay.
+func TestHTTPSinkCloseDuringRetry(t *testing.T) {
+	rec := &batchRecorder{status: func(int) int { return http.StatusServiceUnavailable }}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+	sink, err := NewHTTPSink(HTTPConfig{
+		URL:          srv.URL,
+		BatchSize:    1,
+		MinBackoff:   time.Hour,
+		MaxBackoff:   time.Hour,
+		ErrorHandler: func(error) {},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := NewConsoleLogger("info", quietConsoleConfig(), WithSink(sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Info("retried")
+	deadline := time.Now().Add(2 * time.Second)
+	for rec.calls.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	closed := make(chan error, 1)
+	go func() { closed <- logger.Close() }()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close blocked by the retry delay")
+	}
+}
+
+// TestHTTPSinkConcurrentClose verifies every entry logged while closing is either
+// delivered or counted as dropped.
+func TestHTTPSinkConcurrentClose(t *testing.T) {
+	rec := &batchRecorder{}
+	logger, sink := newHTTPSinkLogger(t, rec, HTTPConfig{BatchSize: 2, QueueSize: 1024, DisableGzip: true})
+
+	const writers, perWriter = 8, 50
+	var wg sync.WaitGroup
+	for range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range perWriter {
+				logger.Info("entry")
+			}
+		}()
+	}
+	_ = logger.Close()
+	wg.Wait()
+
+	delivered := 0
+	for _, b := range rec.batches() {
+		delivered += strings.Count(b, "\n")
+	}
+	if total := delivered + int(sink.Dropped()); total != writers*perWriter {
+		t.Errorf("delivered %d + dropped %d = %d, want %d", delivered, sink.Dropped(), total, writers*perWriter)
+	}
+}
@@ -0,0 +1,269 @@
+package dslogger
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultJournaldSocket is the datagram socket systemd-journald listens on for native protocol entries.
+const DefaultJournaldSocket = "/run/systemd/journal/socket"
+
+// JournaldConfig holds configuration options for a JournaldSink.
+type JournaldConfig struct {
+	// SocketPath defaults to DefaultJournaldSocket.
+	SocketPath string
+
+	// Identifier is sent as SYSLOG_IDENTIFIER when the logger has no service name.
+	// Defaults to the executable name.
+	Identifier string
+
+	// Severities overrides the default level-to-PRIORITY mapping per level.
+	// Journald priorities are syslog severities.
+	Severities map[zapcore.Level]SyslogSeverity
+
+	// Level filters entries on top of the logger's own level. A nil value accepts every entry.
+	Level zapcore.LevelEnabler
+
+	// EncoderConfig controls how time-typed field values are rendered.
+	// A zero value uses DefaultTextEncoderConfig.
+	EncoderConfig zapcore.EncoderConfig
+}
+
+// JournaldSink is a zapcore.Core that writes entries to systemd-journald using the
+// native journal protocol, so every field stays individually queryable with journalctl.
+// Attach it with WithSink.
+//
+// The level becomes PRIORITY, the logger's service name SYSLOG_IDENTIFIER, the caller
+// CODE_FILE/CODE_LINE/CODE_FUNC, and each field an uppercase journal key
+// (request_id becomes REQUEST_ID). Entries too large for a single datagram are
+// passed to journald through a sealed memfd.
+type JournaldSink struct {
+	cfg        *JournaldConfig
+	conn       *net.UnixConn
+	addr       *net.UnixAddr
+	identifier string
+	fields     *dsConsoleEncoder // accumulates context fields from With()
+}
+
+// NewJournaldSink creates a JournaldSink bound to a local datagram socket.
+func NewJournaldSink(cfg JournaldConfig) (*JournaldSink, error) {
+	if cfg.SocketPath == "" {
+		cfg.SocketPath = DefaultJournaldSocket
+	}
+	if cfg.Identifier == "" {
+		cfg.Identifier = filepath.Base(os.Args[0])
+	}
+	severities := defaultSyslogSeverities()
+	for lvl, sev := range cfg.Severities {
+		severities[lvl] = sev
+	}
+	cfg.Severities = severities
+	if cfg.Level == nil {
+		cfg.Level = zapcore.DebugLevel
+	}
+	if cfg.EncoderConfig.EncodeTime == nil {
+		cfg.EncoderConfig = DefaultTextEncoderConfig
+	}
+
+	if _, err := os.Stat(cfg.SocketPath); err != nil {
+		return nil, fmt.Errorf("dslogger: journald socket: %w", err)
+	}
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("dslogger: journald socket: %w", err)
+	}
+	return &JournaldSink{
+		cfg:        &cfg,
+		conn:       conn,
+		addr:       &net.UnixAddr{Name: cfg.SocketPath, Net: "unixgram"},
+		identifier: cfg.Identifier,
+		fields:     newDSConsoleEncoder(&Config{}, cfg.EncoderConfig, ""),
+	}, nil
+}
+
+// Enabled reports whether the sink accepts entries at the given level.
+func (s *JournaldSink) Enabled(lvl zapcore.Level) bool {
+	return s.cfg.Level.Enabled(lvl)
+}
+
+// With returns a copy of the sink carrying the given context fields.
+func (s *JournaldSink) With(fields []zapcore.Field) zapcore.Core {
+	clone := *s
+	clone.fields = s.fields.Clone().(*dsConsoleEncoder)
+	for _, f := range fields {
+		f.AddTo(clone.fields)
+	}
+	return &clone
+}
+
+// withService implements serviceCore: the service name becomes SYSLOG_IDENTIFIER.
+func (s *JournaldSink) withService(name string) zapcore.Core {
+	clone := *s
+	clone.identifier = name
+	return &clone
+}
+
+// Check adds the sink to the checked entry if the level is enabled.
+func (s *JournaldSink) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if s.Enabled(ent.Level) {
+		return ce.AddCore(ent, s)
+	}
+	return ce
+}
+
+// Write serializes and sends a single entry.
+func (s *JournaldSink) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	enc := s.fields
+	if len(fields) > 0 {
+		enc = s.fields.Clone().(*dsConsoleEncoder)
+		for _, f := range fields {
+			f.AddTo(enc)
+		}
+	}
+
+	buf := _pool.Get()
+	defer buf.Free()
+
+	sev, ok := s.cfg.Severities[ent.Level]
+	if !ok {
+		sev = SeverityNotice
+	}
+	appendJournalField(buf, "MESSAGE", ent.Message)
+	appendJournalField(buf, "PRIORITY", strconv.Itoa(int(sev)))
+	appendJournalField(buf, "SYSLOG_IDENTIFIER", s.identifier)
+	if ent.Caller.Defined {
+		appendJournalField(buf, "CODE_FILE", ent.Caller.File)
+		appendJournalField(buf, "CODE_LINE", strconv.Itoa(ent.Caller.Line))
+		if ent.Caller.Function != "" {
+			appendJournalField(buf, "CODE_FUNC", ent.Caller.Function)
+		}
+	}
+	for _, p := range enc.pairs {
+		appendJournalField(buf, journalFieldName(p.key), p.val)
+	}
+	// The captured stack goes to the native STACKTRACE field, error stacks as user fields
+	for _, p := range sinkStackPairs(fields) {
+		name := "STACKTRACE"
+		if p.key != sinkStacktraceKey {
+			name = journalFieldName(p.key)
+		}
+		appendJournalField(buf, name, p.val)
+	}
+
+	return s.send(buf.Bytes())
+}
+
+// send writes the serialized entry as one datagram, falling back to a memfd
+// when the entry exceeds the socket's maximum datagram size.
+func (s *JournaldSink) send(data []byte) error {
+	_, err := s.conn.WriteToUnix(data, s.addr)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, syscall.EMSGSIZE) && !errors.Is(err, syscall.ENOBUFS) {
+		return err
+	}
+
+	f, err := journalMemfd(data)
+	if err != nil {
+		return fmt.Errorf("dslogger: journald large entry: %w", err)
+	}
+	defer f.Close()
+	_, _, err = s.conn.WriteMsgUnix(nil, journalRights(f), s.addr)
+	return err
+}
+
+// Sync is a no-op, entries are sent as they arrive.
+func (s *JournaldSink) Sync() error {
+	return nil
+}
+
+// Close closes the sending socket. Logger.Close calls it for sinks attached via WithSink.
+func (s *JournaldSink) Close() error {
+	return s.conn.Close()
+}
+
+// appendJournalField serializes one KEY=value pair. Values containing a newline
+// use the binary form: KEY\n, little-endian uint64 length, value, \n.
+func appendJournalField(buf *buffer.Buffer, key, val string) {
+	buf.AppendString(key)
+	if !strings.Contains(val, "\n") {
+		buf.AppendByte('=')
+		buf.AppendString(val)
+		buf.AppendByte('\n')
+		return
+	}
+	buf.AppendByte('\n')
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(val)))
+	_, _ = buf.Write(size[:])
+	buf.AppendString(val)
+	buf.AppendByte('\n')
+}
+
+// reservedJournalFields are the user journal fields with a meaning to journald and
+// journalctl, set by the sink itself or describing the entry. A field key mapping to one
+// of them is prefixed with F_ so it cannot spoof the entry's metadata.
+var reservedJournalFields = map[string]bool{
+	"MESSAGE":            true,
+	"MESSAGE_ID":         true,
+	"PRIORITY":           true,
+	"CODE_FILE":          true,
+	"CODE_LINE":          true,
+	"CODE_FUNC":          true,
+	"ERRNO":              true,
+	"INVOCATION_ID":      true,
+	"USER_INVOCATION_ID": true,
+	"SYSLOG_FACILITY":    true,
+	"SYSLOG_IDENTIFIER":  true,
+	"SYSLOG_PID":         true,
+	"SYSLOG_TIMESTAMP":   true,
+	"SYSLOG_RAW":         true,
+	"DOCUMENTATION":      true,
+	"TID":                true,
+	"UNIT":               true,
+	"USER_UNIT":          true,
+	"STACKTRACE":         true,
+}
+
+// journalFieldName maps a field key to a valid journal field name: uppercase ASCII
+// letters, digits and underscores, not starting with an underscore (reserved for
+// trusted fields) or a digit, at most 64 characters. Reserved names get an F_ prefix.
+func journalFieldName(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key) && b.Len() < 64; i++ {
+		c := key[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+			c -= 'a' - 'A'
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		default:
+			c = '_'
+		}
+		if b.Len() == 0 && c == '_' {
+			continue
+		}
+		if b.Len() == 0 && c >= '0' && c <= '9' {
+			b.WriteString("F_")
+		}
+		b.WriteByte(c)
+	}
+	if b.Len() == 0 {
+		return "FIELD"
+	}
+	name := b.String()
+	if reservedJournalFields[name] {
+		return "F_" + name
+	}
+	return name
+}
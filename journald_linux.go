@@ -0,0 +1,48 @@
+package dslogger
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// journalMemfd copies data into a sealed memfd, the transport journald expects
+// for entries larger than a datagram. Kernels without memfd support fall back to
+// an unlinked temporary file in /dev/shm, as sd_journal_send does.
+func journalMemfd(data []byte) (*os.File, error) {
+	fd, err := unix.MemfdCreate("dslogger-journal", unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING)
+	if err != nil {
+		return journalTempFile(data)
+	}
+	f := os.NewFile(uintptr(fd), "dslogger-journal")
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return nil, err
+	}
+	seals := unix.F_SEAL_SHRINK | unix.F_SEAL_GROW | unix.F_SEAL_WRITE | unix.F_SEAL_SEAL
+	if _, err := unix.FcntlInt(f.Fd(), unix.F_ADD_SEALS, seals); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// journalTempFile is the pre-memfd fallback of journalMemfd.
+func journalTempFile(data []byte) (*os.File, error) {
+	f, err := os.CreateTemp("/dev/shm", "dslogger-journal-")
+	if err != nil {
+		return nil, err
+	}
+	_ = os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// journalRights builds the SCM_RIGHTS control message passing f to journald.
+func journalRights(f *os.File) []byte {
+	return syscall.UnixRights(int(f.Fd()))
+}
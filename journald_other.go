@@ -0,0 +1,18 @@
+//go:build !linux
+
+package dslogger
+
+import (
+	"errors"
+	"os"
+)
+
+// journalMemfd is only available on Linux, the sole platform running journald.
+func journalMemfd([]byte) (*os.File, error) {
+	return nil, errors.New("journald is only supported on linux")
+}
+
+// journalRights is only available on Linux, the sole platform running journald.
+func journalRights(*os.File) []byte {
+	return nil
+}
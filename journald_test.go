@@ -0,0 +1,229 @@
+//go:build linux
+
+package dslogger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// journalTestListener binds a Unix datagram socket standing in for journald.
+func journalTestListener(t *testing.T) (*net.UnixConn, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn, path
+}
+
+// readJournalEntry receives one entry, following an SCM_RIGHTS fd if present.
+func readJournalEntry(t *testing.T, conn *net.UnixConn) map[string]string {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 1<<20)
+	oob := make([]byte, 64)
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := buf[:n]
+
+	if oobn > 0 {
+		msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+		if err != nil {
+			t.Fatal(err)
+		}
+		fds, err := syscall.ParseUnixRights(&msgs[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		f := os.NewFile(uintptr(fds[0]), "journal-fd")
+		defer f.Close()
+		if data, err = io.ReadAll(io.NewSectionReader(f, 0, 1<<30)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return parseJournalEntry(t, data)
+}
+
+// parseJournalEntry decodes the native journal protocol.
+func parseJournalEntry(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	out := make(map[string]string)
+	for len(data) > 0 {
+		nl := bytes.IndexByte(data, '\n')
+		if nl < 0 {
+			t.Fatalf("truncated entry: %q", data)
+		}
+		line := data[:nl]
+		data = data[nl+1:]
+		if key, val, ok := bytes.Cut(line, []byte("=")); ok {
+			out[string(key)] = string(val)
+			continue
+		}
+		size := binary.LittleEndian.Uint64(data[:8])
+		out[string(line)] = string(data[8 : 8+size])
+		data = data[8+size+1:]
+	}
+	return out
+}
+
+// TestJournaldSinkFields checks PRIORITY, SYSLOG_IDENTIFIER, CODE_* and field mapping.
+func TestJournaldSinkFields(t *testing.T) {
+	listener, path := journalTestListener(t)
+	sink, err := NewJournaldSink(JournaldConfig{SocketPath: path, Identifier: "fallback"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := NewConsoleLogger("info", quietConsoleConfig(), WithSink(sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	logger.Info("no service")
+	got := readJournalEntry(t, listener)
+	if got["SYSLOG_IDENTIFIER"] != "fallback" {
+		t.Errorf("SYSLOG_IDENTIFIER = %q, want fallback", got["SYSLOG_IDENTIFIER"])
+	}
+
+	logger.WithService("auth").Warn("login failed", "request_id", "r-1", "user.name", "james", "_private", 1,
+		"message", "spoofed", "_priority", 0)
+	got = readJournalEntry(t, listener)
+
+	want := map[string]string{
+		"MESSAGE":           "login failed",
+		"PRIORITY":          "4",
+		"SYSLOG_IDENTIFIER": "auth",
+		"REQUEST_ID":        "r-1",
+		"USER_NAME":         "james",
+		"PRIVATE":           "1",
+		"F_MESSAGE":         "spoofed",
+		"F_PRIORITY":        "0",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+	if _, ok := got["SERVICE"]; ok {
+		t.Errorf("service rendered as a field instead of SYSLOG_IDENTIFIER: %v", got)
+	}
+	if !strings.HasSuffix(got["CODE_FILE"], "journald_test.go") || got["CODE_LINE"] == "" {
+		t.Errorf("caller not mapped to CODE_FILE/CODE_LINE: %v", got)
+	}
+	if !strings.HasSuffix(got["CODE_FUNC"], "TestJournaldSinkFields") {
+		t.Errorf("CODE_FUNC = %q", got["CODE_FUNC"])
+	}
+}
+
+// TestJournaldSinkStack checks that a captured stack is sent as STACKTRACE.
+func TestJournaldSinkStack(t *testing.T) {
+	listener, path := journalTestListener(t)
+	sink, err := NewJournaldSink(JournaldConfig{SocketPath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := quietConsoleConfig()
+	cfg.StacktraceLevel = "error"
+	logger, err := NewConsoleLogger("info", cfg, WithSink(sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	logger.Error("failed")
+
+	got := readJournalEntry(t, listener)
+	if !strings.HasPrefix(got["STACKTRACE"], dsloggerPkg+".TestJournaldSinkStack\n\t") {
+		t.Errorf("STACKTRACE = %q", got["STACKTRACE"])
+	}
+	if _, ok := got["F_STACKTRACE"]; ok {
+		t.Errorf("stack sent as a user field: %v", got)
+	}
+}
+
+// TestJournaldSinkMultiline checks the binary length-prefixed encoding for multi-line values.
+func TestJournaldSinkMultiline(t *testing.T) {
+	listener, path := journalTestListener(t)
+	sink, err := NewJournaldSink(JournaldConfig{SocketPath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	logger, err := NewConsoleLogger("info", quietConsoleConfig(), WithSink(sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The message is sent as is, only the text formats escape its newlines
+	logger.Info("line1\nline2", "body", struct{ Text string }{"a\nb"})
+
+	got := readJournalEntry(t, listener)
+	if got["MESSAGE"] != "line1\nline2" {
+		t.Errorf("MESSAGE = %q, want multi-line value", got["MESSAGE"])
+	}
+	if got["BODY"] != `{Text=a\nb}` {
+		t.Errorf("BODY = %q", got["BODY"])
+	}
+}
+
+// TestJournaldSinkLargeEntry checks the memfd fallback for entries larger than a datagram.
+func TestJournaldSinkLargeEntry(t *testing.T) {
+	listener, path := journalTestListener(t)
+	sink, err := NewJournaldSink(JournaldConfig{SocketPath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	logger, err := NewConsoleLogger("info", quietConsoleConfig(), WithSink(sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	big := strings.Repeat("x", 512*1024)
+	logger.Info("large", "payload", big)
+
+	got := readJournalEntry(t, listener)
+	if got["MESSAGE"] != "large" || got["PAYLOAD"] != big {
+		t.Errorf("large entry not delivered intact (payload len %d)", len(got["PAYLOAD"]))
+	}
+}
+
+// TestJournalFieldName checks key normalisation.
+func TestJournalFieldName(t *testing.T) {
+	tests := map[string]string{
+		"request_id": "REQUEST_ID",
+		"http.path":  "HTTP_PATH",
+		"__secret":   "SECRET",
+		"2fa":        "F_2FA",
+		"":           "FIELD",
+		"message":    "F_MESSAGE",
+		"_priority":  "F_PRIORITY",
+		"code.file":  "F_CODE_FILE",
+		"priority_x": "PRIORITY_X",
+	}
+	for in, want := range tests {
+		if got := journalFieldName(in); got != want {
+			t.Errorf("journalFieldName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// TestJournaldSinkMissingSocket verifies construction fails without a journald socket.
+func TestJournaldSinkMissingSocket(t *testing.T) {
+	if _, err := NewJournaldSink(JournaldConfig{SocketPath: filepath.Join(t.TempDir(), "none")}); err == nil {
+		t.Error("expected error for missing socket")
+	}
+}
@@ -5,10 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"runtime"
 	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
@@ -34,6 +36,9 @@ const (
 	TraceIDKey ctxKey = "trace_id"
 	// SpanIDKey is the context key used by WithContext when extracting an OpenTelemetry span
 	SpanIDKey ctxKey = "span_id"
+	// ParentSpanIDKey is the key WithContext logs the span ID of a remote span context
+	// under, the caller's span, such as one extracted from incoming trace headers
+	ParentSpanIDKey ctxKey = "parent_span_id"
 )
 
 // dsloggerCallerSkip is the number of stack frames between the user's call and the underlying
@@ -52,7 +57,12 @@ type Logger struct {
 	lumberjackLogger *lumberjack.Logger
 	level            zap.AtomicLevel
 	serviceName      string
-	customFields     []zap.Field // tracks fields for the Fields() getter
+	customFields     []zap.Field    // tracks fields for the Fields() getter
+	sinks            []zapcore.Core // additional outputs attached via WithSink
+	sinkLogger       atomic.Pointer[zap.SugaredLogger]
+	extractors       []ContextExtractor // per-logger extractors added via WithContextExtractor
+	span             trace.Span         // recording span of the WithContext context, if any
+	callerSkip       int                // added by WithCallerSkip once the options have run
 	mu               sync.Mutex
 }
 
@@ -110,7 +120,11 @@ func (l *Logger) SetLogLevel(level string) error {
 // WithContext returns a new logger that attaches common fields extracted
 // from the provided context:
 //   - dslogger.RequestIDKey and dslogger.TraceIDKey (typed context keys)
-//   - OpenTelemetry trace.SpanContext (trace_id, span_id) if the span is valid
+//   - OpenTelemetry trace.SpanContext (trace_id, span_id) if the span is valid, its
+//     trace ID replacing the one under TraceIDKey, the span ID of a remote span context
+//     logged as parent_span_id
+//   - fields stashed with ContextWithFields
+//   - the fields returned by the registered ContextExtractors, global ones first
 //
 // String-keyed context values are ignored to avoid cross-package collisions.
 func (l *Logger) WithContext(ctx context.Context) *Logger {
@@ -118,31 +132,15 @@ func (l *Logger) WithContext(ctx context.Context) *Logger {
 		return l
 	}
 
-	kv := make([]any, 0, 8)
-
-	// Typed dslogger keys
-	if requestID, _ := ctx.Value(RequestIDKey).(string); requestID != "" {
-		kv = append(kv, string(RequestIDKey), requestID)
-	}
-	if traceID, _ := ctx.Value(TraceIDKey).(string); traceID != "" {
-		kv = append(kv, string(TraceIDKey), traceID)
-	}
-
-	// OpenTelemetry span context
-	if span := trace.SpanFromContext(ctx); span != nil {
-		sc := span.SpanContext()
-		if sc.HasTraceID() {
-			kv = append(kv, string(TraceIDKey), sc.TraceID().String())
-		}
-		if sc.HasSpanID() {
-			kv = append(kv, string(SpanIDKey), sc.SpanID().String())
-		}
-	}
-
-	if len(kv) == 0 {
+	kv, span := takeSpan(l.appendContextFields(nil, ctx))
+	if len(kv) == 0 && span == nil {
 		return l
 	}
-	return l.WithFields(kv...)
+	newLogger := l.WithFields(kv...)
+	if span != nil {
+		newLogger.span = span
+	}
+	return newLogger
 }
 
 // WithFields returns a new logger with the specified structured fields attached.
@@ -153,12 +151,17 @@ func (l *Logger) WithFields(fields ...any) *Logger {
 	fields = normalizeFields(fields)
 
 	zapFields := make([]zap.Field, 0, len(fields)/2)
-	for i := 0; i < len(fields); i += 2 {
+	for i := 0; i < len(fields); i++ {
+		if f, ok := fields[i].(zap.Field); ok {
+			zapFields = append(zapFields, f)
+			continue
+		}
 		key, ok := fields[i].(string)
 		if !ok {
 			key = fmt.Sprintf("%v", fields[i])
 		}
-		zapFields = append(zapFields, zap.Any(key, fields[i+1]))
+		zapFields = append(zapFields, l.config.fieldFor(key, fields[i+1]))
+		i++
 	}
 
 	newFields := make([]zap.Field, 0, len(l.customFields)+len(zapFields))
@@ -170,7 +173,11 @@ func (l *Logger) WithFields(fields ...any) *Logger {
 		lumberjackLogger: l.lumberjackLogger,
 		level:            l.level,
 		serviceName:      l.serviceName,
-		customFields:     newFields,
+		customFields:     l.config.mergedFields(newFields),
+		sinks:            l.sinks,
+		extractors:       l.extractors,
+		span:             l.span,
+		callerSkip:       l.callerSkip,
 	}
 	if c := l.consoleLogger.Load(); c != nil {
 		newLogger.consoleLogger.Store(c.Desugar().With(zapFields...).Sugar())
@@ -178,6 +185,9 @@ func (l *Logger) WithFields(fields ...any) *Logger {
 	if f := l.fileLogger.Load(); f != nil {
 		newLogger.fileLogger.Store(f.Desugar().With(zapFields...).Sugar())
 	}
+	if s := l.sinkLogger.Load(); s != nil {
+		newLogger.sinkLogger.Store(s.Desugar().With(zapFields...).Sugar())
+	}
 	return newLogger
 }
 
@@ -192,21 +202,21 @@ func (l *Logger) WithService(serviceName string, options ...zap.Option) *Logger
 		level:            l.level,
 		serviceName:      serviceName,
 		customFields:     slices.Clone(l.customFields),
+		sinks:            l.sinks,
+		extractors:       l.extractors,
+		span:             l.span,
+		callerSkip:       l.callerSkip,
 	}
 
-	zapOpts := []zap.Option{zap.AddCaller(), zap.AddCallerSkip(dsloggerCallerSkip)}
+	// Rebuilt outputs need the WithCallerSkip skip again
+	skip := zap.AddCallerSkip(l.callerSkip)
+	zapOpts := []zap.Option{zap.AddCaller(), zap.AddCallerSkip(dsloggerCallerSkip), skip}
 	zapOpts = append(zapOpts, options...)
 
 	// Console: rebuild with the service name baked into the encoder
-	var consEncoder zapcore.Encoder
-	if l.config.ConsoleFormat == LogFormatJSON {
-		consEncoder = zapcore.NewJSONEncoder(l.config.ConsoleConfig)
-	} else {
-		consEncoder = newDSConsoleEncoder(l.config, l.config.ConsoleConfig, serviceName)
-	}
-
+	consEncoder := newConsoleEncoder(l.config, serviceName)
 	consWriter := zapcore.Lock(zapcore.AddSync(l.config.consoleOut()))
-	consCore := zapcore.NewCore(consEncoder, consWriter, l.level)
+	consCore := l.config.wrapCore(zapcore.NewCore(consEncoder, consWriter, l.level))
 	consSugar := zap.New(consCore, zapOpts...).Sugar()
 
 	// For JSON console, add service as a structured field
@@ -225,7 +235,7 @@ func (l *Logger) WithService(serviceName string, options ...zap.Option) *Logger
 			newLogger.fileLogger.Store(base.With(zap.String("service", serviceName)).Sugar())
 		} else {
 			fileEncoder := newDSConsoleEncoder(l.config, l.config.FileConfig, serviceName)
-			fileCore := zapcore.NewCore(fileEncoder, zapcore.AddSync(l.lumberjackLogger), l.level)
+			fileCore := l.config.wrapCore(zapcore.NewCore(fileEncoder, zapcore.AddSync(l.lumberjackLogger), l.level))
 			fileSugar := zap.New(fileCore, zapOpts...).Sugar()
 			if len(l.customFields) > 0 {
 				fileSugar = fileSugar.Desugar().With(l.customFields...).Sugar()
@@ -234,6 +244,11 @@ func (l *Logger) WithService(serviceName string, options ...zap.Option) *Logger
 		}
 	}
 
+	// Sinks: rebuilt from the raw cores so the previous service name does not linger
+	if len(l.sinks) > 0 {
+		newLogger.sinkLogger.Store(buildSinkZap(l.config, l.sinks, serviceName, l.customFields, append([]zap.Option{skip}, options...)...))
+	}
+
 	return newLogger
 }
 
@@ -257,6 +272,91 @@ func (l *Logger) Error(msg string, fields ...any) {
 	l.logMessage(zapcore.ErrorLevel, msg, fields...)
 }
 
+// DebugContext logs a debug-level message with the fields WithContext would extract from
+// ctx, without deriving a logger.
+func (l *Logger) DebugContext(ctx context.Context, msg string, fields ...any) {
+	if l.level.Enabled(zapcore.DebugLevel) {
+		l.logMessage(zapcore.DebugLevel, msg, l.contextFields(ctx, fields)...)
+	}
+}
+
+// InfoContext logs an informational message with the fields WithContext would extract
+// from ctx, without deriving a logger.
+func (l *Logger) InfoContext(ctx context.Context, msg string, fields ...any) {
+	if l.level.Enabled(zapcore.InfoLevel) {
+		l.logMessage(zapcore.InfoLevel, msg, l.contextFields(ctx, fields)...)
+	}
+}
+
+// WarnContext logs a warning message with the fields WithContext would extract from ctx,
+// without deriving a logger.
+func (l *Logger) WarnContext(ctx context.Context, msg string, fields ...any) {
+	if l.level.Enabled(zapcore.WarnLevel) {
+		l.logMessage(zapcore.WarnLevel, msg, l.contextFields(ctx, fields)...)
+	}
+}
+
+// ErrorContext logs an error message with the fields WithContext would extract from ctx,
+// without deriving a logger.
+func (l *Logger) ErrorContext(ctx context.Context, msg string, fields ...any) {
+	if l.level.Enabled(zapcore.ErrorLevel) {
+		l.logMessage(zapcore.ErrorLevel, msg, l.contextFields(ctx, fields)...)
+	}
+}
+
+// Debugf logs a debug-level message formatted with fmt.Sprintf.
+func (l *Logger) Debugf(format string, args ...any) {
+	if l.level.Enabled(zapcore.DebugLevel) {
+		l.logMessage(zapcore.DebugLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+// Infof logs an informational message formatted with fmt.Sprintf.
+func (l *Logger) Infof(format string, args ...any) {
+	if l.level.Enabled(zapcore.InfoLevel) {
+		l.logMessage(zapcore.InfoLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+// Warnf logs a warning message formatted with fmt.Sprintf.
+func (l *Logger) Warnf(format string, args ...any) {
+	if l.level.Enabled(zapcore.WarnLevel) {
+		l.logMessage(zapcore.WarnLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+// Errorf logs an error message formatted with fmt.Sprintf.
+func (l *Logger) Errorf(format string, args ...any) {
+	if l.level.Enabled(zapcore.ErrorLevel) {
+		l.logMessage(zapcore.ErrorLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+// Err logs err and msg at Error level. The error is attached under the "error" key with
+// its chain of causes and any stack it carries, followed by the optional fields.
+func (l *Logger) Err(err error, msg string, fields ...any) {
+	l.logMessage(zapcore.ErrorLevel, msg, append([]any{"error", err}, fields...)...)
+}
+
+// LogDepth logs msg at lvl with the caller depth frames above the caller of LogDepth, for
+// adapters and helpers wrapping the Logger. LogDepth(0, ...) reports the same caller as
+// Info and friends, WithCallerSkip included. Levels above Error are logged at Error,
+// LogDepth never exits or panics.
+func (l *Logger) LogDepth(depth int, lvl zapcore.Level, msg string, fields ...any) {
+	lvl = min(lvl, zapcore.ErrorLevel)
+	if !l.level.Enabled(lvl) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(depth+2+l.callerSkip, pcs[:]) // skip runtime.Callers and LogDepth
+	l.logAt(zapcore.Entry{
+		Level:   lvl,
+		Time:    time.Now(),
+		Message: msg,
+		Caller:  callerFromPC(pcs[0]),
+	}, fields...)
+}
+
 // Fatal logs a message at error level with a [FATAL] tag, flushes buffered output,
 // then calls os.Exit(1).
 // Deferred functions are NOT run.
@@ -278,6 +378,17 @@ func (l *Logger) Panic(msg string, fields ...any) {
 // Callers should defer logger.Sync() at program exit to avoid losing recent log lines.
 // Errors returned by Sync on non-file writers (stdout/stderr on most platforms) are filtered.
 func (l *Logger) Sync() error {
+	errs := []error{l.syncOutputs()}
+	if s := l.sinkLogger.Load(); s != nil {
+		if err := s.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// syncOutputs flushes the console and file outputs.
+func (l *Logger) syncOutputs() error {
 	var errs []error
 
 	if c := l.consoleLogger.Load(); c != nil {
@@ -293,17 +404,19 @@ func (l *Logger) Sync() error {
 	return errors.Join(errs...)
 }
 
-// Close flushes outstanding writes and releases the file handle held by lumberjack (if any).
+// Close flushes outstanding writes and releases the file handle held by lumberjack (if any),
+// as well as any sink attached via WithSink that implements io.Closer.
 // After Close the logger should not be used.
 func (l *Logger) Close() error {
-	syncErr := l.Sync()
+	// Sinks are flushed by closeSinks, so a sink waiting to retry a delivery is cut short
+	// by its Close rather than held up by a Sync first.
+	errs := []error{l.syncOutputs()}
 
 	if l.lumberjackLogger != nil {
-		if err := l.lumberjackLogger.Close(); err != nil {
-			return errors.Join(syncErr, err)
-		}
+		errs = append(errs, l.lumberjackLogger.Close())
 	}
-	return syncErr
+	errs = append(errs, closeSinks(l.sinks))
+	return errors.Join(errs...)
 }
 
 // logMessage is the hot path. It gates on the current level BEFORE any formatting,
@@ -314,17 +427,115 @@ func (l *Logger) logMessage(lvl zapcore.Level, msg string, fields ...any) {
 	if !l.level.Enabled(lvl) {
 		return
 	}
-	fields = normalizeFields(fields)
+	fields, forced := takeForceStack(fields)
+	fields, span := l.takeEntrySpan(fields)
+	fields = l.config.errorFields(normalizeFields(fields))
+	stacked := hasStackField(fields)
+	if !stacked && (forced || l.config.stackEnabled(lvl)) {
+		// skip logMessage and the exported method that called it
+		frames := captureStack(2, l.config.stackDepth())
+		fields = append(fields, zap.Inline(stackField{frames: frames}))
+		stacked = true
+	}
+	consFields, fileFields, sinkFields := fields, fields, fields
+	if stacked {
+		consFields = stackKeyed(fields, l.config.ConsoleConfig.StacktraceKey)
+		fileFields = stackKeyed(fields, l.config.FileConfig.StacktraceKey)
+		sinkFields = stackKeyed(fields, sinkStacktraceKey)
+	}
 
 	if cons := l.consoleLogger.Load(); cons != nil {
-		logStructured(cons, lvl, msg, fields...)
+		logStructured(cons, lvl, msg, consFields...)
 	}
 
 	if f := l.fileLogger.Load(); f != nil {
-		logStructured(f, lvl, msg, fields...)
+		logStructured(f, lvl, msg, fileFields...)
+	}
+
+	if s := l.sinkLogger.Load(); s != nil {
+		logStructured(s, lvl, msg, sinkFields...)
+	}
+
+	if span != nil && l.config.spanEnabled(lvl) {
+		l.recordSpan(span, zapcore.Entry{Level: lvl, Time: time.Now(), Message: msg}, sweetenFields(fields))
 	}
 }
 
+// logAt logs like logMessage but takes the entry's time and caller from ent instead of
+// the clock and the frame zap derives from the stack. Bridges that know the original
+// call site use it, such as SlogHandler with the record's time and PC. A zero time or an
+// undefined caller omits it from the entry.
+func (l *Logger) logAt(ent zapcore.Entry, fields ...any) {
+	if !l.level.Enabled(ent.Level) {
+		return
+	}
+	fields, forced := takeForceStack(fields)
+	fields, span := l.takeEntrySpan(fields)
+	fields = l.config.errorFields(normalizeFields(fields))
+	stacked := hasStackField(fields)
+	if !stacked && (forced || l.config.stackEnabled(ent.Level)) && ent.Caller.Defined {
+		frames := captureStackFrom(ent.Caller.PC, l.config.stackDepth())
+		fields = append(fields, zap.Inline(stackField{frames: frames}))
+		stacked = true
+	}
+	zapFields := sweetenFields(fields)
+	consFields, fileFields, sinkFields := zapFields, zapFields, zapFields
+	if stacked {
+		consFields = sweetenFields(stackKeyed(fields, l.config.ConsoleConfig.StacktraceKey))
+		fileFields = sweetenFields(stackKeyed(fields, l.config.FileConfig.StacktraceKey))
+		sinkFields = sweetenFields(stackKeyed(fields, sinkStacktraceKey))
+	}
+
+	if cons := l.consoleLogger.Load(); cons != nil {
+		writeAt(cons, ent, consFields)
+	}
+
+	if f := l.fileLogger.Load(); f != nil {
+		writeAt(f, ent, fileFields)
+	}
+
+	if s := l.sinkLogger.Load(); s != nil {
+		writeAt(s, ent, sinkFields)
+	}
+
+	if span != nil && l.config.spanEnabled(ent.Level) {
+		l.recordSpan(span, ent, zapFields)
+	}
+}
+
+// writeAt writes an entry through s with the time and caller of ent.
+func writeAt(s *zap.SugaredLogger, ent zapcore.Entry, fields []zap.Field) {
+	ce := s.Desugar().Check(ent.Level, ent.Message)
+	if ce == nil {
+		return
+	}
+	ce.Time = ent.Time
+	ce.Caller = ent.Caller
+	ce.Write(fields...)
+}
+
+// sweetenFields converts a normalized key-value list to zap fields the way the sugared
+// logger does: a zap.Field takes a single slot, any other pair becomes zap.Any.
+func sweetenFields(kv []any) []zap.Field {
+	fields := make([]zap.Field, 0, len(kv)/2)
+	for i := 0; i < len(kv); i++ {
+		if f, ok := kv[i].(zap.Field); ok {
+			fields = append(fields, f)
+			continue
+		}
+		if i+1 == len(kv) {
+			break
+		}
+		key, ok := kv[i].(string)
+		if !ok {
+			key = fmt.Sprintf("%v", kv[i])
+		}
+		fields = append(fields, zap.Any(key, kv[i+1]))
+		i++
+	}
+	return fields
+}
+
 func logStructured(s *zap.SugaredLogger, lvl zapcore.Level, msg string, kv ...any) {
 	switch lvl {
 	case zapcore.DebugLevel:
@@ -338,14 +549,21 @@ func logStructured(s *zap.SugaredLogger, lvl zapcore.Level, msg string, kv ...an
 	}
 }
 
-// normalizeFields ensures an even number of args, appending a placeholder value
-// if the caller passed an odd count. This is more permissive than panicking and
-// matches zap's SugaredLogger DPanic behavior without the panic.
+// normalizeFields ensures every key has a value, appending a placeholder value if the
+// caller passed a dangling key. A zap.Field takes a single slot, like in sweetenFields.
+// This is more permissive than panicking and matches zap's SugaredLogger DPanic
+// behavior without the panic.
 func normalizeFields(fields []any) []any {
-	if len(fields)%2 == 0 {
-		return fields
+	for i := 0; i < len(fields); i++ {
+		if _, ok := fields[i].(zap.Field); ok {
+			continue
+		}
+		if i+1 == len(fields) {
+			return append(fields, "<missing>")
+		}
+		i++
 	}
-	return append(fields, "<missing>")
+	return fields
 }
 
 // isIgnorableSyncError reports whether err is a benign sync error from stdout/stderr.
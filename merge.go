@@ -0,0 +1,162 @@
+package dslogger
+
+import (
+	"fmt"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// FieldMerge selects what happens when an entry carries the same key more than once,
+// for example a key given to WithFields and again to the logging call.
+type FieldMerge string
+
+// Supported field merge policies. Keys are compared up to the first namespace,
+// fields without a key (inline marshalers) are always kept.
+const (
+	MergeLastWins  FieldMerge = "last-wins"  // the latest value replaces earlier ones, in place of the first
+	MergeFirstWins FieldMerge = "first-wins" // later values of a key are dropped
+	MergeKeepAll   FieldMerge = "keep-all"   // every field is written, as zap does
+)
+
+// parseFieldMerge validates m, an empty value meaning MergeKeepAll. Unknown values
+// yield MergeKeepAll and an error.
+func parseFieldMerge(m FieldMerge) (FieldMerge, error) {
+	switch m {
+	case "":
+		return MergeKeepAll, nil
+	case MergeLastWins, MergeFirstWins, MergeKeepAll:
+		return m, nil
+	}
+	return MergeKeepAll, fmt.Errorf("unknown field merge policy %q", m)
+}
+
+// mergeCore wraps an output core so that the fields added with With and the fields of
+// each entry reach its encoder merged under a FieldMerge policy. The With fields are
+// kept as fields instead of being encoded upfront, so that an entry field can replace
+// one of them.
+type mergeCore struct {
+	zapcore.Core // without the With fields
+	policy       FieldMerge
+	fields       []zapcore.Field
+}
+
+// wrapCore returns core wrapped in a mergeCore, or core itself with MergeKeepAll.
+func (c *Config) wrapCore(core zapcore.Core) zapcore.Core {
+	policy, _ := parseFieldMerge(c.FieldMerge)
+	if policy == MergeKeepAll {
+		return core
+	}
+	return &mergeCore{Core: core, policy: policy}
+}
+
+// With implements zapcore.Core.
+func (m *mergeCore) With(fields []zapcore.Field) zapcore.Core {
+	if len(fields) == 0 {
+		return m
+	}
+	return &mergeCore{Core: m.Core, policy: m.policy, fields: m.merge(fields)}
+}
+
+// Check implements zapcore.Core.
+func (m *mergeCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if m.Enabled(ent.Level) {
+		return ce.AddCore(ent, m)
+	}
+	return ce
+}
+
+// Write implements zapcore.Core.
+func (m *mergeCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return m.Core.Write(ent, m.merge(fields))
+}
+
+// merge returns the With fields followed by fields, merged under the policy.
+// fields is returned as is when there are no With fields and no duplicate key.
+func (m *mergeCore) merge(fields []zapcore.Field) []zapcore.Field {
+	if len(m.fields) == 0 {
+		if !hasDuplicateKey(fields) {
+			return fields
+		}
+		return mergeFields(m.policy, fields)
+	}
+	all := make([]zapcore.Field, 0, len(m.fields)+len(fields))
+	all = append(append(all, m.fields...), fields...)
+	if !hasDuplicateKey(all) {
+		return all
+	}
+	return mergeFields(m.policy, all)
+}
+
+// fieldKey returns the key fields are merged on, the key of an errorField for errors.
+// It returns "" for fields that are never merged.
+func fieldKey(f zapcore.Field) string {
+	if f.Type == zapcore.InlineMarshalerType {
+		if ef, ok := f.Interface.(errorField); ok {
+			return ef.key
+		}
+		return ""
+	}
+	return f.Key
+}
+
+// mergeScope returns the number of leading fields subject to merging, the fields after
+// the first namespace belonging to that namespace.
+func mergeScope(fields []zapcore.Field) int {
+	for i, f := range fields {
+		if f.Type == zapcore.NamespaceType {
+			return i
+		}
+	}
+	return len(fields)
+}
+
+// hasDuplicateKey reports whether two fields of the merge scope share a key.
+func hasDuplicateKey(fields []zapcore.Field) bool {
+	scope := fields[:mergeScope(fields)]
+	for i := 1; i < len(scope); i++ {
+		key := fieldKey(scope[i])
+		if key == "" {
+			continue
+		}
+		for j := range i {
+			if fieldKey(scope[j]) == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mergeFields returns a new slice holding fields merged under policy. Each key keeps the
+// position of its first occurrence.
+func mergeFields(policy FieldMerge, fields []zapcore.Field) []zapcore.Field {
+	n := mergeScope(fields)
+	out := make([]zapcore.Field, 0, len(fields))
+	index := make(map[string]int, n)
+	for _, f := range fields[:n] {
+		key := fieldKey(f)
+		if key == "" {
+			out = append(out, f)
+			continue
+		}
+		i, seen := index[key]
+		switch {
+		case !seen:
+			index[key] = len(out)
+			out = append(out, f)
+		case policy == MergeLastWins:
+			out[i] = f
+		}
+	}
+	return append(out, fields[n:]...)
+}
+
+// mergedFields returns fields merged under the configured policy, fields itself when
+// nothing needs merging.
+func (c *Config) mergedFields(fields []zapcore.Field) []zapcore.Field {
+	policy, _ := parseFieldMerge(c.FieldMerge)
+	if policy == MergeKeepAll || !hasDuplicateKey(fields) {
+		return fields
+	}
+	return mergeFields(policy, fields)
+}
@@ -0,0 +1,169 @@
+package dslogger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// topLevelKeys returns how many times each top-level key appears in the JSON object
+// line, failing the test when the line is not valid JSON.
+func topLevelKeys(t *testing.T, line []byte) map[string]int {
+	t.Helper()
+	if !json.Valid(line) {
+		t.Fatalf("invalid JSON: %s", line)
+	}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	counts := make(map[string]int)
+	if _, err := dec.Token(); err != nil { // {
+		t.Fatal(err)
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[key.(string)]++
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return counts
+}
+
+// mergeTestContext returns a context carrying a trace ID under TraceIDKey and a different
+// one in its OpenTelemetry span.
+func mergeTestContext() context.Context {
+	traceID, _ := trace.TraceIDFromHex("4f9c2a7b1e6d8c3f0a2b4c6d8e1f9a0b")
+	spanID, _ := trace.SpanIDFromHex("1a2b3c4d5e6f7a8b")
+	ctx := context.WithValue(context.Background(), TraceIDKey, "typed-trace")
+	return trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+}
+
+// mergeTestLogger returns a console logger writing format to buf with the policy.
+func mergeTestLogger(t *testing.T, buf *bytes.Buffer, format LogFormat, policy FieldMerge) *Logger {
+	t.Helper()
+	cfg := NewDefaultConfig()
+	cfg.ConsoleWriter = buf
+	cfg.ConsoleFormat = format
+	cfg.FieldMerge = policy
+	logger, err := NewConsoleLogger("info", &cfg, WithCustomFields("k", "custom", "env", "prod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+// TestFieldMergeJSON checks each policy across WithCustomFields, WithFields, WithContext
+// and the call fields, and that the merged entries are valid JSON without duplicate keys.
+func TestFieldMergeJSON(t *testing.T) {
+	tests := []struct {
+		policy FieldMerge
+		k      string
+		trace  string
+		dup    bool
+	}{
+		{"", "", "", true},
+		{MergeLastWins, "call", "4f9c2a7b1e6d8c3f0a2b4c6d8e1f9a0b", false},
+		{MergeFirstWins, "custom", "4f9c2a7b1e6d8c3f0a2b4c6d8e1f9a0b", false},
+		{MergeKeepAll, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := mergeTestLogger(t, &buf, LogFormatJSON, tt.policy)
+			logger.WithFields("k", "derived").WithContext(mergeTestContext()).Info("merged", "k", "call")
+
+			// The span's trace ID replaces the typed one under every policy
+			counts := topLevelKeys(t, buf.Bytes())
+			if counts[string(TraceIDKey)] != 1 {
+				t.Errorf("trace_id written %d times: %s", counts[string(TraceIDKey)], buf.String())
+			}
+			if dup := counts["k"] > 1; dup != tt.dup {
+				t.Fatalf("duplicate keys = %v, want %v: %s", dup, tt.dup, buf.String())
+			}
+			if tt.dup {
+				if counts["k"] != 3 {
+					t.Errorf("keep-all counts = %v", counts)
+				}
+				return
+			}
+			entry := decodeEntry(t, &buf)
+			if entry["k"] != tt.k || entry[string(TraceIDKey)] != tt.trace || entry["env"] != "prod" {
+				t.Errorf("unexpected entry: %v", entry)
+			}
+		})
+	}
+}
+
+// TestFieldMergeText checks the text and pretty formats write a merged key once, at the
+// position of its first occurrence.
+func TestFieldMergeText(t *testing.T) {
+	for _, format := range []LogFormat{LogFormatText, LogFormatPretty} {
+		var buf bytes.Buffer
+		logger := mergeTestLogger(t, &buf, format, MergeLastWins)
+		logger.WithFields("k", "derived").Info("merged", "k", "call", "after", 1)
+
+		out := buf.String()
+		if strings.Count(out, "custom") != 0 || strings.Count(out, "derived") != 0 || strings.Count(out, "call") != 1 {
+			t.Errorf("%s: unexpected output %q", format, out)
+		}
+		if strings.Index(out, "call") > strings.Index(out, "prod") {
+			t.Errorf("%s: merged key moved: %q", format, out)
+		}
+	}
+}
+
+// TestFieldMergeFields checks Fields reflects the policy and repeated services or errors
+// sharing a key are merged.
+func TestFieldMergeFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := mergeTestLogger(t, &buf, LogFormatJSON, MergeLastWins)
+
+	derived := logger.WithFields("k", "derived", "k", "again")
+	if fields := derived.Fields(); len(fields) != 2 || fields[0].Key != "k" || fields[0].String != "again" {
+		t.Errorf("Fields() = %v", fields)
+	}
+
+	derived.WithService("a").WithService("b").Info("m", "error", "text", "error", errors.New("boom"))
+	counts := topLevelKeys(t, buf.Bytes())
+	if counts["service"] != 1 || counts["error"] != 1 {
+		t.Errorf("counts = %v: %s", counts, buf.String())
+	}
+	if entry := decodeEntry(t, &buf); entry["service"] != "b" || entry["error"] != "boom" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+}
+
+// TestMergeFieldsNamespace checks fields after a namespace are left alone.
+func TestMergeFieldsNamespace(t *testing.T) {
+	fields := []zap.Field{zap.String("a", "1"), zap.Namespace("ns"), zap.String("a", "2"), zap.String("a", "3")}
+	if hasDuplicateKey(fields) {
+		t.Error("keys inside the namespace reported as duplicates")
+	}
+	fields = append([]zap.Field{zap.String("a", "0")}, fields...)
+	got := mergeFields(MergeLastWins, fields)
+	if len(got) != 4 || got[0].String != "1" || got[2].String != "2" {
+		t.Errorf("mergeFields = %v", got)
+	}
+}
+
+// TestParseFieldMerge checks the empty and unknown policies.
+func TestParseFieldMerge(t *testing.T) {
+	if m, err := parseFieldMerge(""); m != MergeKeepAll || err != nil {
+		t.Errorf("empty policy = %q, %v", m, err)
+	}
+	if m, err := parseFieldMerge("newest"); m != MergeKeepAll || err == nil {
+		t.Errorf("unknown policy = %q, %v", m, err)
+	}
+}
@@ -0,0 +1,204 @@
+package dslogger
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// HTTPMiddlewareOptions configures HTTPMiddleware.
+type HTTPMiddlewareOptions struct {
+	// RequestIDHeader is the header the request ID is read from and echoed in on the
+	// response. Empty means X-Request-ID.
+	RequestIDHeader string
+
+	// GenerateRequestID returns the ID of requests arriving without one. Nil generates
+	// 32 random hexadecimal digits.
+	GenerateRequestID func() string
+
+	// TraceHeaders extracts the traceparent or B3 headers of the request with
+	// ExtractTraceHeaders, so that the request logger carries trace_id and parent_span_id.
+	TraceHeaders bool
+
+	// SkipPaths lists URL paths, such as health checks, logged without an access line.
+	// Their handlers still get the request logger.
+	SkipPaths []string
+
+	// Skip, when set, reports whether the access line of r is left out, in addition
+	// to SkipPaths.
+	Skip func(r *http.Request) bool
+
+	// Level returns the level of the access line for a status code. Nil logs 5xx at
+	// Error, 4xx at Warn and the rest at Info.
+	Level func(status int) zapcore.Level
+
+	// Combined writes the access line as its Apache combined log format rendering instead
+	// of a message with structured fields.
+	Combined bool
+}
+
+// HTTPMiddleware returns net/http middleware logging one access line per request through
+// l. It reads the request ID from the request header, or generates one, stores it under
+// RequestIDKey and echoes it on the response. The request context carries l, so handlers
+// get a request-scoped logger from FromContext. The access line holds the method, path,
+// status, bytes written, duration, remote address and user agent. A nil opts is the same
+// as the zero HTTPMiddlewareOptions.
+func HTTPMiddleware(l *Logger, opts *HTTPMiddlewareOptions) func(http.Handler) http.Handler {
+	var o HTTPMiddlewareOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.RequestIDHeader == "" {
+		o.RequestIDHeader = "X-Request-ID"
+	}
+	if o.GenerateRequestID == nil {
+		o.GenerateRequestID = newRequestID
+	}
+	if o.Level == nil {
+		o.Level = statusLevel
+	}
+	o.SkipPaths = slices.Clone(o.SkipPaths)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			requestID := r.Header.Get(o.RequestIDHeader)
+			if requestID == "" {
+				requestID = o.GenerateRequestID()
+			}
+			w.Header().Set(o.RequestIDHeader, requestID)
+
+			ctx := context.WithValue(r.Context(), RequestIDKey, requestID)
+			if o.TraceHeaders {
+				ctx = ExtractTraceHeaders(ctx, r.Header)
+			}
+			r = r.WithContext(NewContext(ctx, l))
+
+			rw := &responseRecorder{ResponseWriter: w}
+			next.ServeHTTP(rw, r)
+
+			if slices.Contains(o.SkipPaths, r.URL.Path) || (o.Skip != nil && o.Skip(r)) {
+				return
+			}
+			status := rw.statusCode()
+			lvl := o.Level(status)
+			if !l.level.Enabled(lvl) {
+				return
+			}
+			if o.Combined {
+				l.LogDepth(0, lvl, combinedLogLine(r, start, status, rw.bytes), l.contextFields(ctx, nil)...)
+				return
+			}
+			l.LogDepth(0, lvl, "http request", l.contextFields(ctx, []any{
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", status,
+				"bytes", rw.bytes,
+				"duration", time.Since(start),
+				"remote_addr", r.RemoteAddr,
+				"user_agent", r.UserAgent(),
+			})...)
+		})
+	}
+}
+
+// statusLevel is the default HTTPMiddlewareOptions.Level.
+func statusLevel(status int) zapcore.Level {
+	switch {
+	case status >= 500:
+		return zapcore.ErrorLevel
+	case status >= 400:
+		return zapcore.WarnLevel
+	}
+	return zapcore.InfoLevel
+}
+
+// newRequestID returns 32 random hexadecimal digits.
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// combinedLogLine renders a request in the Apache combined log format:
+//
+//	host - user [time] "method uri proto" status bytes "referer" "user-agent"
+func combinedLogLine(r *http.Request, start time.Time, status int, bytes int64) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	user := "-"
+	if name, _, ok := r.BasicAuth(); ok && name != "" {
+		user = name
+	}
+	size := "-"
+	if bytes > 0 {
+		size = strconv.FormatInt(bytes, 10)
+	}
+	return fmt.Sprintf("%s - %s [%s] %q %d %s %q %q",
+		host, user, start.Format("02/Jan/2006:15:04:05 -0700"),
+		r.Method+" "+r.URL.RequestURI()+" "+r.Proto, status, size, r.Referer(), r.UserAgent())
+}
+
+// responseRecorder records the status code and body size written through an
+// http.ResponseWriter. Unwrap gives http.ResponseController access to the original.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *responseRecorder) WriteHeader(status int) {
+	if w.status == 0 && status >= 200 { // informational responses are not final
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter.
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher when the original writer does.
+func (w *responseRecorder) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker when the original writer does.
+func (w *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap returns the original writer.
+func (w *responseRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// statusCode returns the status written, 200 when the handler wrote nothing.
+func (w *responseRecorder) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
@@ -0,0 +1,127 @@
+package dslogger
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// TestHTTPMiddleware checks the access line, its level per status, the request ID and
+// the request logger.
+func TestHTTPMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slogTestLogger(t, &buf)
+	handler := HTTPMiddleware(logger, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Debug("handling")
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			_, _ = io.WriteString(w, "hello")
+		}
+	}))
+
+	tests := []struct {
+		path, level string
+		status      float64
+	}{
+		{"/hello", "INFO", 200},
+		{"/missing", "WARN", 404},
+		{"/broken", "ERROR", 500},
+	}
+	for _, tt := range tests {
+		buf.Reset()
+		req := httptest.NewRequest(http.MethodGet, tt.path+"?q=1", nil)
+		req.Header.Set("User-Agent", "test-agent")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("%s: lines = %q", tt.path, lines)
+		}
+		handled, access := decodeEntry(t, bytes.NewBufferString(lines[0])), decodeEntry(t, bytes.NewBufferString(lines[1]))
+		requestID := rec.Header().Get("X-Request-ID")
+		if len(requestID) != 32 || handled["request_id"] != requestID || access["request_id"] != requestID {
+			t.Errorf("%s: request ID %q, handler %v, access %v", tt.path, requestID, handled["request_id"], access["request_id"])
+		}
+		if lvl, _ := access["level"].(string); strings.TrimSpace(lvl) != tt.level || access["status"] != tt.status ||
+			access["method"] != "GET" || access["path"] != tt.path || access["user_agent"] != "test-agent" ||
+			access["remote_addr"] != "192.0.2.1:1234" || access["bytes"] != float64(rec.Body.Len()) || access["duration"] == nil {
+			t.Errorf("%s: unexpected access line: %v", tt.path, access)
+		}
+	}
+}
+
+// TestHTTPMiddlewareOptions checks a propagated request ID, the trace headers, the path
+// filters and a custom level.
+func TestHTTPMiddlewareOptions(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slogTestLogger(t, &buf)
+	handler := HTTPMiddleware(logger, &HTTPMiddlewareOptions{
+		RequestIDHeader: "X-Correlation-ID",
+		TraceHeaders:    true,
+		SkipPaths:       []string{"/healthz"},
+		Skip:            func(r *http.Request) bool { return r.Method == http.MethodOptions },
+		Level:           func(int) zapcore.Level { return zapcore.DebugLevel },
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/jobs", nil)
+	req.Header.Set("X-Correlation-ID", "corr-1")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	entry := decodeEntry(t, &buf)
+	if lvl, _ := entry["level"].(string); strings.TrimSpace(lvl) != "DEBUG" || entry["request_id"] != "corr-1" ||
+		entry[string(TraceIDKey)] != "4bf92f3577b34da6a3ce929d0e0e4736" || entry["status"] != float64(200) {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+	if rec.Header().Get("X-Correlation-ID") != "corr-1" || !rec.Flushed {
+		t.Errorf("headers = %v, flushed = %v", rec.Header(), rec.Flushed)
+	}
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/healthz", nil),
+		httptest.NewRequest(http.MethodOptions, "/jobs", nil),
+	} {
+		buf.Reset()
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		if buf.Len() != 0 {
+			t.Errorf("%s %s logged: %s", req.Method, req.URL.Path, buf.String())
+		}
+	}
+}
+
+// TestHTTPMiddlewareCombined checks the Apache combined format access line.
+func TestHTTPMiddlewareCombined(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slogTestLogger(t, &buf)
+	handler := HTTPMiddleware(logger, &HTTPMiddlewareOptions{Combined: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "created")
+	}))
+
+	req := httptest.NewRequest(http.MethodPut, "/items/7?force=1", nil)
+	req.SetBasicAuth("frank", "secret")
+	req.Header.Set("Referer", "https://example.com/")
+	req.Header.Set("User-Agent", "curl/8.0")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	entry := decodeEntry(t, &buf)
+	line := regexp.MustCompile(`^192\.0\.2\.1 - frank \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "PUT /items/7\?force=1 HTTP/1\.1" 200 7 "https://example\.com/" "curl/8\.0"$`)
+	if msg, _ := entry["message"].(string); !line.MatchString(msg) {
+		t.Errorf("combined line = %q", entry["message"])
+	}
+	if entry["request_id"] == nil || entry["status"] != nil {
+		t.Errorf("unexpected fields: %v", entry)
+	}
+}
@@ -0,0 +1,148 @@
+package dslogger
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// Default reconnect backoff bounds shared by the network sinks.
+const (
+	defaultMinBackoff  = 100 * time.Millisecond
+	defaultMaxBackoff  = 30 * time.Second
+	defaultDialTimeout = 5 * time.Second
+)
+
+// errReconnectBackoff is returned by redialConn while it waits before the next dial attempt.
+var errReconnectBackoff = errors.New("dslogger: remote unavailable, waiting to reconnect")
+
+// redialConn wraps a net.Conn that is re-established on demand after a failure.
+// Dial attempts are spaced with exponential backoff so a dead peer costs one
+// time comparison per write instead of a blocking dial on every log call.
+type redialConn struct {
+	mu           sync.Mutex
+	dial         func() (net.Conn, error)
+	conn         net.Conn
+	writeTimeout time.Duration
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+	backoff      time.Duration
+	nextAttempt  time.Time
+	connects     uint64 // successful dials, including the first one
+	closed       bool
+}
+
+// newRedialConn returns a redialConn using dial, with zero backoff bounds replaced by defaults.
+func newRedialConn(dial func() (net.Conn, error), writeTimeout, minBackoff, maxBackoff time.Duration) *redialConn {
+	if minBackoff <= 0 {
+		minBackoff = defaultMinBackoff
+	}
+	if maxBackoff < minBackoff {
+		maxBackoff = max(defaultMaxBackoff, minBackoff)
+	}
+	return &redialConn{
+		dial:         dial,
+		writeTimeout: writeTimeout,
+		minBackoff:   minBackoff,
+		maxBackoff:   maxBackoff,
+	}
+}
+
+// connect dials a fresh connection unless still inside the backoff window.
+// The caller must hold c.mu.
+func (c *redialConn) connect() error {
+	if c.closed {
+		return net.ErrClosed
+	}
+	if time.Now().Before(c.nextAttempt) {
+		return errReconnectBackoff
+	}
+	conn, err := c.dial()
+	if err != nil {
+		c.fail()
+		return err
+	}
+	c.conn = conn
+	c.connects++
+	c.backoff = 0
+	c.nextAttempt = time.Time{}
+	return nil
+}
+
+// fail doubles the backoff (bounded by maxBackoff) and schedules the next dial attempt.
+// The caller must hold c.mu.
+func (c *redialConn) fail() {
+	if c.backoff == 0 {
+		c.backoff = c.minBackoff
+	} else {
+		c.backoff = min(c.backoff*2, c.maxBackoff)
+	}
+	c.nextAttempt = time.Now().Add(c.backoff)
+}
+
+// drop closes and forgets the current connection. The caller must hold c.mu.
+func (c *redialConn) drop() {
+	if c.conn != nil {
+		_ = c.conn.Close()
+		c.conn = nil
+	}
+}
+
+// writeLocked writes p on the current connection, applying the write deadline if configured.
+// The caller must hold c.mu.
+func (c *redialConn) writeLocked(p []byte) (int, error) {
+	if c.writeTimeout > 0 {
+		_ = c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	}
+	return c.conn.Write(p)
+}
+
+// Write sends p, connecting first if needed. A failed write drops the connection and is
+// retried once on a fresh one, since stream peers commonly close idle connections.
+func (c *redialConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		if err := c.connect(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := c.writeLocked(p)
+	if err == nil {
+		return n, nil
+	}
+	c.drop()
+
+	if err := c.connect(); err != nil {
+		return 0, err
+	}
+	n, err = c.writeLocked(p)
+	if err != nil {
+		c.drop()
+		c.fail()
+	}
+	return n, err
+}
+
+// Connected reports whether a connection is currently established.
+func (c *redialConn) Connected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn != nil
+}
+
+// Close closes the current connection and prevents further reconnects.
+func (c *redialConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
@@ -0,0 +1,387 @@
+package dslogger
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultSpoolMaxBytes bounds the on-disk spool when NetworkConfig.SpoolMaxBytes is zero.
+const defaultSpoolMaxBytes = 16 << 20
+
+// errSpoolFull is returned when an entry is dropped because the spool reached its size limit.
+var errSpoolFull = errors.New("dslogger: network spool full, entry dropped")
+
+// NetworkConfig holds configuration options for a NetworkSink.
+type NetworkConfig struct {
+	// Network is one of "tcp", "udp", "unix" or "unixgram", including the
+	// "4"/"6" suffixed variants of tcp and udp.
+	Network string
+	Address string
+
+	// Format selects the line format, LogFormatJSON (default) or LogFormatText.
+	Format LogFormat
+
+	// EncoderConfig defaults to DefaultJSONEncoderConfig or DefaultTextEncoderConfig
+	// depending on Format.
+	EncoderConfig zapcore.EncoderConfig
+
+	// SpoolFile is the path of the on-disk buffer used while the remote is unreachable.
+	// Spooled entries are replayed in order once the connection is re-established, including
+	// entries left over from a previous run. When empty, entries are dropped while disconnected.
+	SpoolFile string
+
+	// SpoolMaxBytes bounds the spool file size, defaults to 16 MiB.
+	// Entries that would exceed it are dropped and counted in NetworkStats.BytesDropped.
+	SpoolMaxBytes int64
+
+	// Level filters entries on top of the logger's own level. A nil value accepts every entry.
+	Level zapcore.LevelEnabler
+
+	DialTimeout  time.Duration // defaults to 5s
+	WriteTimeout time.Duration // zero means no write deadline
+	MinBackoff   time.Duration // first reconnect delay, defaults to 100ms
+	MaxBackoff   time.Duration // reconnect delay cap, defaults to 30s
+}
+
+// NetworkStats is a snapshot of a NetworkWriter's counters.
+type NetworkStats struct {
+	Connected    bool
+	Connects     uint64 // successful dials, so Connects-1 is the number of reconnects
+	BytesSent    uint64 // bytes delivered to the remote, including replayed spool entries
+	BytesSpooled uint64 // bytes written to the spool while disconnected
+	BytesDropped uint64 // bytes lost because spooling was disabled or the spool was full
+	SpoolSize    int64  // bytes currently waiting in the spool
+}
+
+// NetworkWriter is a zapcore.WriteSyncer streaming log lines to a remote over TCP,
+// UDP or a Unix socket. Each Write is one entry. While the remote is down, entries
+// are appended to a bounded spool file and replayed on the first successful write
+// after the connection comes back.
+type NetworkWriter struct {
+	mu    sync.Mutex
+	conn  *redialConn
+	spool *spoolFile
+
+	sent    atomic.Uint64
+	spooled atomic.Uint64
+	dropped atomic.Uint64
+}
+
+// NewNetworkWriter creates a NetworkWriter. The remote does not need to be reachable:
+// the first dial failure only starts the reconnect backoff.
+func NewNetworkWriter(cfg NetworkConfig) (*NetworkWriter, error) {
+	switch cfg.Network {
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6", "unix", "unixgram":
+	default:
+		return nil, fmt.Errorf("dslogger: unsupported network %q", cfg.Network)
+	}
+	if cfg.DialTimeout == 0 {
+		cfg.DialTimeout = defaultDialTimeout
+	}
+	if cfg.SpoolMaxBytes == 0 {
+		cfg.SpoolMaxBytes = defaultSpoolMaxBytes
+	}
+
+	d := &net.Dialer{Timeout: cfg.DialTimeout}
+	dial := func() (net.Conn, error) { return d.Dial(cfg.Network, cfg.Address) }
+	w := &NetworkWriter{conn: newRedialConn(dial, cfg.WriteTimeout, cfg.MinBackoff, cfg.MaxBackoff)}
+
+	if cfg.SpoolFile != "" {
+		spool, err := openSpoolFile(cfg.SpoolFile, cfg.SpoolMaxBytes)
+		if err != nil {
+			return nil, fmt.Errorf("dslogger: open spool: %w", err)
+		}
+		w.spool = spool
+	}
+
+	w.conn.mu.Lock()
+	_ = w.conn.connect()
+	w.conn.mu.Unlock()
+	return w, nil
+}
+
+// Write sends p, replaying any spooled entries first so ordering is preserved.
+// When the remote is unreachable p is spooled and Write reports success, an error is
+// returned only if the entry had to be dropped.
+func (w *NetworkWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	err := w.replayLocked()
+	if err == nil {
+		var n int
+		if n, err = w.conn.Write(p); err == nil {
+			w.sent.Add(uint64(n))
+			return n, nil
+		}
+	}
+	return w.spoolLocked(p, err)
+}
+
+// spoolLocked appends p to the spool, or drops it and returns cause when spooling
+// is disabled. The caller must hold w.mu.
+func (w *NetworkWriter) spoolLocked(p []byte, cause error) (int, error) {
+	if w.spool == nil {
+		w.dropped.Add(uint64(len(p)))
+		return 0, cause
+	}
+	if err := w.spool.append(p); err != nil {
+		w.dropped.Add(uint64(len(p)))
+		return 0, err
+	}
+	w.spooled.Add(uint64(len(p)))
+	return len(p), nil
+}
+
+// replayLocked sends every spooled entry in order. Entries delivered before a failure
+// are removed from the spool, the rest are kept for the next attempt.
+// The caller must hold w.mu.
+func (w *NetworkWriter) replayLocked() error {
+	if w.spool == nil || w.spool.size == 0 {
+		return nil
+	}
+	return w.spool.drain(func(entry []byte) error {
+		n, err := w.conn.Write(entry)
+		if err == nil {
+			w.sent.Add(uint64(n))
+		}
+		return err
+	})
+}
+
+// Sync tries to flush the spool to the remote. An unreachable remote is not an error,
+// the entries stay spooled.
+func (w *NetworkWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_ = w.replayLocked()
+	return nil
+}
+
+// Connected reports whether the writer currently holds an open connection.
+func (w *NetworkWriter) Connected() bool {
+	return w.conn.Connected()
+}
+
+// Stats returns a snapshot of the writer's counters.
+func (w *NetworkWriter) Stats() NetworkStats {
+	w.conn.mu.Lock()
+	connected, connects := w.conn.conn != nil, w.conn.connects
+	w.conn.mu.Unlock()
+
+	var spoolSize int64
+	w.mu.Lock()
+	if w.spool != nil {
+		spoolSize = w.spool.size
+	}
+	w.mu.Unlock()
+
+	return NetworkStats{
+		Connected:    connected,
+		Connects:     connects,
+		BytesSent:    w.sent.Load(),
+		BytesSpooled: w.spooled.Load(),
+		BytesDropped: w.dropped.Load(),
+		SpoolSize:    spoolSize,
+	}
+}
+
+// Close makes a last replay attempt, then closes the connection and the spool file.
+// Entries still spooled stay on disk and are replayed by the next NetworkWriter
+// opened on the same SpoolFile.
+func (w *NetworkWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_ = w.replayLocked()
+	err := w.conn.Close()
+	if w.spool != nil {
+		err = errors.Join(err, w.spool.close())
+	}
+	return err
+}
+
+// NetworkSink is a zapcore.Core encoding entries as JSON or text lines and streaming
+// them through a NetworkWriter. Attach it with WithSink.
+type NetworkSink struct {
+	zapcore.Core
+	cfg    *NetworkConfig
+	writer *NetworkWriter
+}
+
+// NewNetworkSink creates a NetworkSink.
+func NewNetworkSink(cfg NetworkConfig) (*NetworkSink, error) {
+	if cfg.Format == "" {
+		cfg.Format = LogFormatJSON
+	}
+	if cfg.Level == nil {
+		cfg.Level = zapcore.DebugLevel
+	}
+	if cfg.EncoderConfig.EncodeTime == nil {
+		if cfg.Format == LogFormatText {
+			cfg.EncoderConfig = DefaultTextEncoderConfig
+		} else {
+			cfg.EncoderConfig = DefaultJSONEncoderConfig
+		}
+	}
+
+	w, err := NewNetworkWriter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	s := &NetworkSink{cfg: &cfg, writer: w}
+	s.Core = zapcore.NewCore(s.encoder(""), w, cfg.Level)
+	return s, nil
+}
+
+// encoder builds the line encoder. Text lines render the service name with the
+// default ServiceNameDecorators, like the text log file.
+func (s *NetworkSink) encoder(serviceName string) zapcore.Encoder {
+	if s.cfg.Format != LogFormatText {
+		return zapcore.NewJSONEncoder(s.cfg.EncoderConfig)
+	}
+	cfg := NewDefaultConfig()
+	cfg.NoColor = true
+	encCfg := s.cfg.EncoderConfig
+	encCfg.EncodeLevel = FixedWidthCapitalLevelEncoder(&cfg)
+	return newDSConsoleEncoder(&cfg, encCfg, serviceName)
+}
+
+// withService implements serviceCore. Only the text format renders the service natively,
+// JSON lines carry it as a regular "service" field.
+func (s *NetworkSink) withService(name string) zapcore.Core {
+	if s.cfg.Format != LogFormatText {
+		return s.Core.With([]zapcore.Field{zap.String("service", name)})
+	}
+	return zapcore.NewCore(s.encoder(name), s.writer, s.cfg.Level)
+}
+
+// Writer returns the underlying transport, for connection state and counters.
+func (s *NetworkSink) Writer() *NetworkWriter {
+	return s.writer
+}
+
+// Close closes the transport. Logger.Close calls it for sinks attached via WithSink.
+func (s *NetworkSink) Close() error {
+	return s.writer.Close()
+}
+
+// spoolFile is an append-only file of length-prefixed entries, bounded in size.
+// Each record is a 4-byte big-endian length followed by the entry bytes, so
+// multi-line entries (stack traces) survive replay intact.
+type spoolFile struct {
+	path    string
+	f       *os.File
+	size    int64
+	maxSize int64
+}
+
+// openSpoolFile opens (or creates) the spool, keeping entries left by a previous run.
+func openSpoolFile(path string, maxSize int64) (*spoolFile, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &spoolFile{path: path, f: f, size: info.Size(), maxSize: maxSize}, nil
+}
+
+// append writes one record, refusing it if the spool would exceed maxSize.
+func (s *spoolFile) append(p []byte) error {
+	recLen := int64(4 + len(p))
+	if s.size+recLen > s.maxSize {
+		return errSpoolFull
+	}
+	rec := make([]byte, 4, recLen)
+	binary.BigEndian.PutUint32(rec, uint32(len(p)))
+	rec = append(rec, p...)
+	if _, err := s.f.Write(rec); err != nil {
+		return err
+	}
+	s.size += recLen
+	return nil
+}
+
+// drain calls send for each record in order. On the first failure the records not
+// yet delivered are moved to the front of the file and the error is returned.
+func (s *spoolFile) drain(send func([]byte) error) error {
+	if _, err := s.f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	r := bufio.NewReader(io.LimitReader(s.f, s.size))
+	var offset int64
+	var sendErr error
+	for offset < s.size {
+		var hdr [4]byte
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			break // torn record from a crash, discard the tail
+		}
+		entry := make([]byte, binary.BigEndian.Uint32(hdr[:]))
+		if _, err := io.ReadFull(r, entry); err != nil {
+			break
+		}
+		if sendErr = send(entry); sendErr != nil {
+			break
+		}
+		offset += int64(4 + len(entry))
+	}
+	if sendErr == nil {
+		offset = s.size
+	}
+	return errors.Join(sendErr, s.compact(offset))
+}
+
+// compact drops the first n bytes of the spool.
+func (s *spoolFile) compact(n int64) error {
+	if n == 0 {
+		return nil
+	}
+	if n >= s.size {
+		if err := s.f.Truncate(0); err != nil {
+			return err
+		}
+		s.size = 0
+		return nil
+	}
+
+	rest := make([]byte, s.size-n)
+	if _, err := s.f.ReadAt(rest, n); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, rest, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	_ = s.f.Close()
+	s.f = f
+	s.size = int64(len(rest))
+	return nil
+}
+
+// close closes the spool file, leaving its content on disk.
+func (s *spoolFile) close() error {
+	return s.f.Close()
+}
@@ -0,0 +1,251 @@
+package dslogger
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// lineServer accepts TCP connections on addr and forwards every received line.
+func lineServer(t *testing.T, addr string) (net.Listener, chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	lines := make(chan string, 64)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				sc := bufio.NewScanner(conn)
+				for sc.Scan() {
+					lines <- sc.Text()
+				}
+			}()
+		}
+	}()
+	return ln, lines
+}
+
+// nextLine waits for the next received line.
+func nextLine(t *testing.T, lines chan string) string {
+	t.Helper()
+	select {
+	case l := <-lines:
+		return l
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for line")
+		return ""
+	}
+}
+
+// unusedTCPAddr returns a loopback address nothing is listening on.
+func unusedTCPAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+	return addr
+}
+
+// TestNetworkSinkJSON streams JSON lines over TCP.
+func TestNetworkSinkJSON(t *testing.T) {
+	ln, lines := lineServer(t, "127.0.0.1:0")
+	sink, err := NewNetworkSink(NetworkConfig{Network: "tcp", Address: ln.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := NewConsoleLogger("info", quietConsoleConfig(), WithSink(sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	logger.WithService("billing").Info("charged", "amount", 12)
+
+	var parsed map[string]any
+	if err := json.Unmarshal([]byte(nextLine(t, lines)), &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if parsed["message"] != "charged" || parsed["service"] != "billing" || parsed["amount"] != float64(12) {
+		t.Errorf("unexpected JSON line: %v", parsed)
+	}
+	if st := sink.Writer().Stats(); !st.Connected || st.BytesSent == 0 {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
+// TestNetworkSinkText streams text lines with the service decorators.
+func TestNetworkSinkText(t *testing.T) {
+	ln, lines := lineServer(t, "127.0.0.1:0")
+	sink, err := NewNetworkSink(NetworkConfig{Network: "tcp", Address: ln.Addr().String(), Format: LogFormatText})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := NewConsoleLogger("info", quietConsoleConfig(), WithSink(sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	logger.WithService("auth").WithFields("user", "james").Warn("denied")
+
+	if got := nextLine(t, lines); !strings.HasSuffix(got, " | WARN  | [auth] denied | user: james") {
+		t.Errorf("text line = %q", got)
+	}
+}
+
+// TestNetworkSinkUDP sends one datagram per entry.
+func TestNetworkSinkUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	sink, err := NewNetworkSink(NetworkConfig{Network: "udp", Address: pc.LocalAddr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := NewConsoleLogger("info", quietConsoleConfig(), WithSink(sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	logger.Info("one")
+	logger.Info("two")
+
+	buf := make([]byte, 4096)
+	for _, want := range []string{`"message":"one"`, `"message":"two"`} {
+		_ = pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(buf[:n]), want) {
+			t.Errorf("datagram %q does not contain %s", buf[:n], want)
+		}
+	}
+}
+
+// TestNetworkSinkSpoolReplay spools while the remote is down and replays in order on reconnect.
+func TestNetworkSinkSpoolReplay(t *testing.T) {
+	addr := unusedTCPAddr(t)
+	sink, err := NewNetworkSink(NetworkConfig{
+		Network:    "tcp",
+		Address:    addr,
+		SpoolFile:  filepath.Join(t.TempDir(), "spool"),
+		MinBackoff: time.Millisecond,
+		MaxBackoff: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := NewConsoleLogger("info", quietConsoleConfig(), WithSink(sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	logger.Info("spooled-1")
+	logger.Info("spooled-2")
+	st := sink.Writer().Stats()
+	if st.Connected || st.SpoolSize == 0 || st.BytesSpooled == 0 {
+		t.Fatalf("expected spooled entries while disconnected, stats = %+v", st)
+	}
+
+	_, lines := lineServer(t, addr)
+	time.Sleep(5 * time.Millisecond) // let the backoff window expire
+	logger.Info("live")
+
+	for _, want := range []string{"spooled-1", "spooled-2", "live"} {
+		if got := nextLine(t, lines); !strings.Contains(got, want) {
+			t.Errorf("got %q, want entry %s", got, want)
+		}
+	}
+	st = sink.Writer().Stats()
+	if !st.Connected || st.SpoolSize != 0 {
+		t.Errorf("spool not drained after reconnect, stats = %+v", st)
+	}
+}
+
+// TestNetworkSinkSpoolSurvivesRestart replays entries spooled by a previous writer.
+func TestNetworkSinkSpoolSurvivesRestart(t *testing.T) {
+	addr := unusedTCPAddr(t)
+	spool := filepath.Join(t.TempDir(), "spool")
+
+	w1, err := NewNetworkWriter(NetworkConfig{Network: "tcp", Address: addr, SpoolFile: spool})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w1.Write([]byte("from previous run\n"))
+	_ = w1.Close()
+
+	_, lines := lineServer(t, addr)
+	w2, err := NewNetworkWriter(NetworkConfig{Network: "tcp", Address: addr, SpoolFile: spool})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w2.Close()
+	if err := w2.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := nextLine(t, lines); got != "from previous run" {
+		t.Errorf("replayed line = %q", got)
+	}
+}
+
+// TestNetworkSinkSpoolBounded drops entries once the spool is full.
+func TestNetworkSinkSpoolBounded(t *testing.T) {
+	w, err := NewNetworkWriter(NetworkConfig{
+		Network:       "tcp",
+		Address:       unusedTCPAddr(t),
+		SpoolFile:     filepath.Join(t.TempDir(), "spool"),
+		SpoolMaxBytes: 32,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("0123456789\n")); err != nil {
+		t.Fatalf("first entry should be spooled: %v", err)
+	}
+	if _, err := w.Write([]byte("this entry no longer fits in the spool\n")); err != errSpoolFull {
+		t.Errorf("err = %v, want errSpoolFull", err)
+	}
+	if st := w.Stats(); st.BytesDropped == 0 || st.SpoolSize != 15 {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
+// TestNetworkSinkNoSpoolDrops verifies entries are dropped without a spool file.
+func TestNetworkSinkNoSpoolDrops(t *testing.T) {
+	w, err := NewNetworkWriter(NetworkConfig{Network: "tcp", Address: unusedTCPAddr(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("lost\n")); err == nil {
+		t.Error("expected an error for a dropped entry")
+	}
+	if st := w.Stats(); st.BytesDropped != 5 {
+		t.Errorf("BytesDropped = %d, want 5", st.BytesDropped)
+	}
+}
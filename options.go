@@ -9,19 +9,33 @@ import (
 )
 
 // WithCallerSkip adjusts the caller skip level for accurate file/line reporting.
-// Added on top of the library's baseline skip (dsloggerCallerSkip).
+// Added on top of the library's baseline skip (dsloggerCallerSkip). The skip is applied
+// once every option has run, so options rebuilding the outputs, such as WithSink or
+// WithServiceName, keep it whatever their order.
 func WithCallerSkip(skip int) Option {
 	return func(l *Logger) error {
-		if c := l.consoleLogger.Load(); c != nil {
-			l.consoleLogger.Store(c.Desugar().WithOptions(zap.AddCallerSkip(skip)).Sugar())
-		}
-		if f := l.fileLogger.Load(); f != nil {
-			l.fileLogger.Store(f.Desugar().WithOptions(zap.AddCallerSkip(skip)).Sugar())
-		}
+		l.callerSkip += skip
 		return nil
 	}
 }
 
+// applyCallerSkip adds the skip accumulated by WithCallerSkip to every output.
+func (l *Logger) applyCallerSkip() {
+	if l.callerSkip == 0 {
+		return
+	}
+	skip := zap.AddCallerSkip(l.callerSkip)
+	if c := l.consoleLogger.Load(); c != nil {
+		l.consoleLogger.Store(c.Desugar().WithOptions(skip).Sugar())
+	}
+	if f := l.fileLogger.Load(); f != nil {
+		l.fileLogger.Store(f.Desugar().WithOptions(skip).Sugar())
+	}
+	if s := l.sinkLogger.Load(); s != nil {
+		l.sinkLogger.Store(s.Desugar().WithOptions(skip).Sugar())
+	}
+}
+
 // WithConsoleEncoder sets a custom zapcore.Encoder for the console logger.
 // The stdout writer remains wrapped in zapcore.Lock.
 func WithConsoleEncoder(encoder zapcore.Encoder) Option {
@@ -30,7 +44,7 @@ func WithConsoleEncoder(encoder zapcore.Encoder) Option {
 			return nil
 		}
 		writer := zapcore.Lock(zapcore.AddSync(l.config.consoleOut()))
-		core := zapcore.NewCore(encoder, writer, l.level)
+		core := l.config.wrapCore(zapcore.NewCore(encoder, writer, l.level))
 		l.consoleLogger.Store(zap.New(core, zap.AddCaller(), zap.AddCallerSkip(dsloggerCallerSkip)).Sugar())
 		return nil
 	}
@@ -42,7 +56,7 @@ func WithFileEncoder(encoder zapcore.Encoder) Option {
 		if l.fileLogger.Load() == nil || l.lumberjackLogger == nil {
 			return nil
 		}
-		core := zapcore.NewCore(encoder, zapcore.AddSync(l.lumberjackLogger), l.level)
+		core := l.config.wrapCore(zapcore.NewCore(encoder, zapcore.AddSync(l.lumberjackLogger), l.level))
 		l.fileLogger.Store(zap.New(core, zap.AddCaller(), zap.AddCallerSkip(dsloggerCallerSkip)).Sugar())
 		return nil
 	}
@@ -67,19 +81,22 @@ func WithCustomFields(fields ...any) Option {
 			if !ok {
 				return fmt.Errorf("field key must be a string, got %T", fields[i])
 			}
-			zapFields = append(zapFields, zap.Any(key, fields[i+1]))
+			zapFields = append(zapFields, l.config.fieldFor(key, fields[i+1]))
 		}
 
 		// Create a fresh slice to avoid aliasing with any derived loggers.
-		l.customFields = append(slices.Clone(l.customFields), zapFields...)
+		l.customFields = l.config.mergedFields(append(slices.Clone(l.customFields), zapFields...))
 
-		// Apply to both loggers via zap's With so the encoder receives them.
+		// Apply to every logger via zap's With so the encoder receives them.
 		if c := l.consoleLogger.Load(); c != nil {
 			l.consoleLogger.Store(c.Desugar().With(zapFields...).Sugar())
 		}
 		if f := l.fileLogger.Load(); f != nil {
 			l.fileLogger.Store(f.Desugar().With(zapFields...).Sugar())
 		}
+		if s := l.sinkLogger.Load(); s != nil {
+			l.sinkLogger.Store(s.Desugar().With(zapFields...).Sugar())
+		}
 		return nil
 	}
 }
@@ -147,6 +164,11 @@ func WithServiceName(name string) Option {
 				l.fileLogger.Store(f.Desugar().With(l.customFields...).Sugar())
 			}
 		}
+
+		// Sinks are rebuilt from the raw cores, so custom fields are re-applied there
+		if len(l.sinks) > 0 {
+			l.sinkLogger.Store(buildSinkZap(l.config, l.sinks, name, l.customFields))
+		}
 		return nil
 	}
 }
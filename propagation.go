@@ -0,0 +1,201 @@
+package dslogger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Trace propagation header names, canonicalized by http.Header.
+const (
+	headerTraceparent = "traceparent"
+	headerTracestate  = "tracestate"
+	headerB3          = "b3"
+	headerB3TraceID   = "X-B3-TraceId"
+	headerB3SpanID    = "X-B3-SpanId"
+	headerB3Sampled   = "X-B3-Sampled"
+	headerB3Flags     = "X-B3-Flags"
+)
+
+// TraceHeaders selects the header formats written by InjectTraceHeaders.
+type TraceHeaders int
+
+// Supported trace header formats, combined with |.
+const (
+	TraceHeadersW3C      TraceHeaders = 1 << iota // traceparent and tracestate
+	TraceHeadersB3Single                          // b3
+	TraceHeadersB3Multi                           // X-B3-TraceId, X-B3-SpanId and X-B3-Sampled
+)
+
+// ExtractTraceHeaders returns a copy of ctx carrying the trace context of the W3C
+// traceparent and tracestate headers of h, else of its B3 single or multi headers, as a
+// remote OpenTelemetry span context, so that WithContext logs its trace_id, and the
+// caller's span ID as parent_span_id, without a tracer provider. Invalid headers are ignored. Without trace headers, a new
+// sampled trace ID and span ID are generated. A ctx already carrying a valid span context
+// is returned as is.
+func ExtractTraceHeaders(ctx context.Context, h http.Header) context.Context {
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+	sc, ok := parseTraceparent(h.Get(headerTraceparent))
+	if ok {
+		if ts, err := trace.ParseTraceState(strings.Join(h.Values(headerTracestate), ",")); err == nil {
+			sc = sc.WithTraceState(ts)
+		}
+		return trace.ContextWithRemoteSpanContext(ctx, sc)
+	}
+	if sc, ok = parseB3Single(h.Get(headerB3)); ok {
+		return trace.ContextWithRemoteSpanContext(ctx, sc)
+	}
+	if sc, ok = parseB3Multi(h); ok {
+		return trace.ContextWithRemoteSpanContext(ctx, sc)
+	}
+	return trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    newTraceID(),
+		SpanID:     newSpanID(),
+		TraceFlags: trace.FlagsSampled,
+	}))
+}
+
+// InjectTraceHeaders writes the span context of ctx to h in the given formats, for
+// outgoing requests. It does nothing when ctx carries no valid span context.
+func InjectTraceHeaders(ctx context.Context, h http.Header, formats TraceHeaders) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	traceID, spanID := sc.TraceID().String(), sc.SpanID().String()
+	sampled := "0"
+	if sc.IsSampled() {
+		sampled = "1"
+	}
+
+	if formats&TraceHeadersW3C != 0 {
+		h.Set(headerTraceparent, "00-"+traceID+"-"+spanID+"-"+sc.TraceFlags().String())
+		if ts := sc.TraceState().String(); ts != "" {
+			h.Set(headerTracestate, ts)
+		}
+	}
+	if formats&TraceHeadersB3Single != 0 {
+		h.Set(headerB3, traceID+"-"+spanID+"-"+sampled)
+	}
+	if formats&TraceHeadersB3Multi != 0 {
+		h.Set(headerB3TraceID, traceID)
+		h.Set(headerB3SpanID, spanID)
+		h.Set(headerB3Sampled, sampled)
+	}
+}
+
+// parseTraceparent parses a W3C traceparent header, version-traceid-parentid-flags.
+// Versions above 00 may append fields, version ff is invalid.
+func parseTraceparent(v string) (trace.SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 || !isLowerHex(parts[0]) || !isLowerHex(parts[3]) {
+		return trace.SpanContext{}, false
+	}
+	version, err := hex.DecodeString(parts[0])
+	if err != nil || len(version) != 1 || version[0] == 0xff || (version[0] == 0 && len(parts) != 4) {
+		return trace.SpanContext{}, false
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil || len(flags) != 1 {
+		return trace.SpanContext{}, false
+	}
+	return newRemoteSpanContext(parts[1], parts[2], trace.TraceFlags(flags[0])&trace.FlagsSampled)
+}
+
+// parseB3Single parses a B3 single header, traceid-spanid[-sampled[-parentspanid]].
+// A lone sampling decision carries no trace context.
+func parseB3Single(v string) (trace.SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 2 || len(parts) > 4 {
+		return trace.SpanContext{}, false
+	}
+	var flags trace.TraceFlags
+	if len(parts) > 2 {
+		switch parts[2] {
+		case "1", "d":
+			flags = trace.FlagsSampled
+		case "0":
+		default:
+			return trace.SpanContext{}, false
+		}
+	}
+	return newRemoteSpanContext(padTraceID(parts[0]), parts[1], flags)
+}
+
+// parseB3Multi parses the X-B3-TraceId, X-B3-SpanId, X-B3-Sampled and X-B3-Flags headers.
+func parseB3Multi(h http.Header) (trace.SpanContext, bool) {
+	var flags trace.TraceFlags
+	switch strings.ToLower(h.Get(headerB3Sampled)) {
+	case "1", "true":
+		flags = trace.FlagsSampled
+	case "", "0", "false":
+	default:
+		return trace.SpanContext{}, false
+	}
+	if h.Get(headerB3Flags) == "1" { // debug implies sampled
+		flags = trace.FlagsSampled
+	}
+	return newRemoteSpanContext(padTraceID(h.Get(headerB3TraceID)), h.Get(headerB3SpanID), flags)
+}
+
+// newRemoteSpanContext returns the remote span context of the hex IDs, reporting whether
+// they are valid.
+func newRemoteSpanContext(traceHex, spanHex string, flags trace.TraceFlags) (trace.SpanContext, bool) {
+	traceID, err := trace.TraceIDFromHex(traceHex)
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+	spanID, err := trace.SpanIDFromHex(spanHex)
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: flags,
+		Remote:     true,
+	}), true
+}
+
+// padTraceID left-pads a 64-bit B3 trace ID to 128 bits.
+func padTraceID(id string) string {
+	if len(id) == 16 {
+		return strings.Repeat("0", 16) + id
+	}
+	return id
+}
+
+// isLowerHex reports whether s holds only lowercase hexadecimal digits, as W3C requires.
+// The IDs are checked by trace.TraceIDFromHex and trace.SpanIDFromHex.
+func isLowerHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// newTraceID returns a random, non-zero trace ID.
+func newTraceID() trace.TraceID {
+	var id trace.TraceID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+// newSpanID returns a random, non-zero span ID.
+func newSpanID() trace.SpanID {
+	var id trace.SpanID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
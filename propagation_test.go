@@ -0,0 +1,117 @@
+package dslogger
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestExtractTraceHeaders checks the W3C, B3 single and B3 multi formats, their precedence
+// and the rejection of invalid headers.
+func TestExtractTraceHeaders(t *testing.T) {
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+	tests := []struct {
+		name    string
+		headers map[string]string
+		traceID string // empty for a generated trace
+		sampled bool
+		state   string
+	}{
+		{"traceparent", map[string]string{"traceparent": "00-" + traceID + "-" + spanID + "-01", "tracestate": "vendor=x"}, traceID, true, "vendor=x"},
+		{"future version", map[string]string{"traceparent": "01-" + traceID + "-" + spanID + "-00-extra"}, traceID, false, ""},
+		{"traceparent first", map[string]string{"traceparent": "00-" + traceID + "-" + spanID + "-01", "b3": "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1"}, traceID, true, ""},
+		{"b3 single", map[string]string{"b3": traceID + "-" + spanID + "-d-05e3ac9a4f6e3b90"}, traceID, true, ""},
+		{"b3 single 64-bit", map[string]string{"b3": "a3ce929d0e0e4736-" + spanID}, "0000000000000000a3ce929d0e0e4736", false, ""},
+		{"b3 multi", map[string]string{"X-B3-TraceId": traceID, "X-B3-SpanId": spanID, "X-B3-Sampled": "1"}, traceID, true, ""},
+		{"b3 multi debug", map[string]string{"X-B3-TraceId": traceID, "X-B3-SpanId": spanID, "X-B3-Flags": "1"}, traceID, true, ""},
+		{"uppercase traceparent", map[string]string{"traceparent": "00-" + "4BF92F3577B34DA6A3CE929D0E0E4736" + "-" + spanID + "-01"}, "", true, ""},
+		{"version 00 with extra", map[string]string{"traceparent": "00-" + traceID + "-" + spanID + "-01-x"}, "", true, ""},
+		{"version ff", map[string]string{"traceparent": "ff-" + traceID + "-" + spanID + "-01"}, "", true, ""},
+		{"zero trace", map[string]string{"b3": "00000000000000000000000000000000-" + spanID}, "", true, ""},
+		{"bad sampling", map[string]string{"X-B3-TraceId": traceID, "X-B3-SpanId": spanID, "X-B3-Sampled": "yes"}, "", true, ""},
+		{"none", nil, "", true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := make(http.Header)
+			for k, v := range tt.headers {
+				h.Set(k, v)
+			}
+			sc := trace.SpanContextFromContext(ExtractTraceHeaders(context.Background(), h))
+			if !sc.IsValid() || sc.IsSampled() != tt.sampled || sc.TraceState().String() != tt.state {
+				t.Fatalf("span context = %+v", sc)
+			}
+			if tt.traceID == "" {
+				if sc.IsRemote() || sc.TraceID().String() == traceID {
+					t.Errorf("trace not generated: %+v", sc)
+				}
+				return
+			}
+			if !sc.IsRemote() || sc.TraceID().String() != tt.traceID || sc.SpanID().String() != spanID {
+				t.Errorf("span context = %s/%s remote=%v", sc.TraceID(), sc.SpanID(), sc.IsRemote())
+			}
+		})
+	}
+}
+
+// TestExtractTraceHeadersLogged checks WithContext logs the extracted IDs, the caller's
+// span as the parent, and that a context already traced is kept.
+func TestExtractTraceHeadersLogged(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slogTestLogger(t, &buf)
+	h := http.Header{"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}}
+
+	ctx := ExtractTraceHeaders(context.Background(), h)
+	logger.WithContext(ctx).Info("extracted")
+	entry := decodeEntry(t, &buf)
+	if entry[string(TraceIDKey)] != "4bf92f3577b34da6a3ce929d0e0e4736" ||
+		entry[string(ParentSpanIDKey)] != "00f067aa0ba902b7" || entry[string(SpanIDKey)] != nil {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+
+	// Generated IDs are this service's own
+	buf.Reset()
+	logger.WithContext(ExtractTraceHeaders(context.Background(), http.Header{})).Info("generated")
+	entry = decodeEntry(t, &buf)
+	if entry[string(SpanIDKey)] == nil || entry[string(ParentSpanIDKey)] != nil {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+
+	if again := ExtractTraceHeaders(ctx, http.Header{"B3": {"80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1"}}); again != ctx {
+		t.Error("traced context replaced")
+	}
+}
+
+// TestInjectTraceHeaders checks each format round-trips through ExtractTraceHeaders.
+func TestInjectTraceHeaders(t *testing.T) {
+	state, _ := trace.ParseTraceState("vendor=x")
+	want := trace.SpanContextFromContext(ExtractTraceHeaders(context.Background(), nil)).WithTraceState(state)
+	ctx := trace.ContextWithSpanContext(context.Background(), want)
+
+	for _, format := range []TraceHeaders{TraceHeadersW3C, TraceHeadersB3Single, TraceHeadersB3Multi} {
+		h := make(http.Header)
+		InjectTraceHeaders(ctx, h, format)
+		if len(h) == 0 {
+			t.Fatalf("format %d: no header", format)
+		}
+		got := trace.SpanContextFromContext(ExtractTraceHeaders(context.Background(), h))
+		if got.TraceID() != want.TraceID() || got.SpanID() != want.SpanID() || got.IsSampled() != want.IsSampled() {
+			t.Errorf("format %d: %v, want %v", format, got, want)
+		}
+		if format == TraceHeadersW3C && got.TraceState().String() != "vendor=x" {
+			t.Errorf("tracestate = %q", got.TraceState())
+		}
+	}
+
+	h := make(http.Header)
+	InjectTraceHeaders(context.Background(), h, TraceHeadersW3C|TraceHeadersB3Single)
+	if len(h) != 0 {
+		t.Errorf("headers without span context: %v", h)
+	}
+}
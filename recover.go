@@ -0,0 +1,138 @@
+package dslogger
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RecoveryOptions configures RecoveryMiddleware and GoWithOptions.
+type RecoveryOptions struct {
+	// Repanic panics again with the recovered value once it is logged, to crash the
+	// process or, for HTTP, let net/http abort the connection instead of answering 500.
+	Repanic bool
+}
+
+// RecoveryMiddleware returns net/http middleware recovering the panics of the next
+// handler. A panic is logged through l like Logger.Panic, at Error level with a [PANIC]
+// tag, with the stack of the panic, the request method, path and remote address and the
+// fields WithContext extracts from the request context. The client gets a 500 response
+// when nothing was written yet. http.ErrAbortHandler is passed on without being logged.
+// A nil opts is the same as the zero RecoveryOptions.
+//
+// Placed inside HTTPMiddleware, the entry carries the request ID and the access line
+// reports the 500.
+func RecoveryMiddleware(l *Logger, opts *RecoveryOptions) func(http.Handler) http.Handler {
+	var o RecoveryOptions
+	if opts != nil {
+		o = *opts
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := &responseRecorder{ResponseWriter: w}
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				l.logPanic(r.Context(), v, "method", r.Method, "path", r.URL.Path, "remote_addr", r.RemoteAddr)
+				if o.Repanic {
+					panic(v)
+				}
+				if rw.status == 0 {
+					http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+			}()
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// Go runs fn in a new goroutine, logging a panic of fn through l like RecoveryMiddleware
+// instead of crashing the process.
+func Go(l *Logger, fn func()) {
+	GoWithOptions(l, fn, nil)
+}
+
+// GoWithOptions is Go with options. A nil opts is the same as the zero RecoveryOptions.
+func GoWithOptions(l *Logger, fn func(), opts *RecoveryOptions) {
+	var o RecoveryOptions
+	if opts != nil {
+		o = *opts
+	}
+	go func() {
+		defer func() {
+			if v := recover(); v != nil {
+				l.logPanic(nil, v)
+				if o.Repanic {
+					_ = l.Sync()
+					panic(v)
+				}
+			}
+		}()
+		fn()
+	}()
+}
+
+// logPanic logs the recovered value v with the stack of the panic, reported from the
+// frame that panicked. It must be called by the deferred function that recovered v.
+func (l *Logger) logPanic(ctx context.Context, v any, fields ...any) {
+	if !l.level.Enabled(zapcore.ErrorLevel) {
+		return
+	}
+	frames := capturePanicStack(l.config.stackDepth())
+	if err, ok := v.(error); ok {
+		fields = append(fields, "error", err)
+	} else {
+		fields = append(fields, "panic", v)
+	}
+	fields = append(fields, zap.Inline(stackField{frames: frames}))
+
+	var caller zapcore.EntryCaller
+	if len(frames) > 0 {
+		f := frames[0]
+		caller = zapcore.EntryCaller{Defined: true, PC: f.PC, File: f.File, Line: f.Line, Function: f.Function}
+	}
+	l.logAt(zapcore.Entry{
+		Level:   zapcore.ErrorLevel,
+		Time:    time.Now(),
+		Message: "[PANIC] " + fmt.Sprint(v),
+		Caller:  caller,
+	}, l.contextFields(ctx, fields)...)
+}
+
+// capturePanicStack returns the stack of the panicking goroutine from the frame that
+// panicked, trimmed like captureStack. Called outside a deferred recovery it returns the
+// caller's stack.
+func capturePanicStack(maxDepth int) []runtime.Frame {
+	pcs := make([]uintptr, maxDepth+64) // headroom for the recovery and runtime frames
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	var out []runtime.Frame
+	panicking := false
+	for {
+		frame, more := frames.Next()
+		switch {
+		case frame.Function == "runtime.gopanic":
+			// The frames so far are the deferred recovery's
+			panicking, out = true, out[:0]
+		case panicking && len(out) == 0 && strings.HasPrefix(frame.Function, "runtime."):
+			// runtime.panicmem, runtime.sigpanic and friends
+		case keepFrame(frame):
+			out = append(out, frame)
+		}
+		if !more || (panicking && len(out) == maxDepth) {
+			break
+		}
+	}
+	return out[:min(len(out), maxDepth)]
+}
@@ -0,0 +1,150 @@
+package dslogger
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRecoveryMiddleware checks the panic entry, its caller and stack, the request
+// context and the 500 response seen by the access log.
+func TestRecoveryMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slogTestLogger(t, &buf)
+	var line string
+	handler := HTTPMiddleware(logger, nil)(RecoveryMiddleware(logger, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		line = thisLine()
+		panic("boom")
+	})))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/crash", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d", rec.Code)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("lines = %q", lines)
+	}
+	entry, access := decodeEntry(t, bytes.NewBufferString(lines[0])), decodeEntry(t, bytes.NewBufferString(lines[1]))
+	if lvl, _ := entry["level"].(string); strings.TrimSpace(lvl) != "ERROR" || entry["message"] != "[PANIC] boom" ||
+		entry["panic"] != "boom" || entry["path"] != "/crash" || entry["request_id"] != rec.Header().Get("X-Request-ID") {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+	if caller, _ := entry["caller"].(string); !strings.Contains(caller, "recover_test.go:"+line) {
+		t.Errorf("caller = %q, want recover_test.go:%s", caller, line)
+	}
+	stack, _ := entry["stacktrace"].(string)
+	if !strings.HasPrefix(stack, "github.com/K4rian/dslogger.TestRecoveryMiddleware.func1\n") || strings.Contains(stack, "runtime.gopanic") {
+		t.Errorf("stacktrace = %q", stack)
+	}
+	if access["status"] != float64(500) {
+		t.Errorf("access status = %v", access["status"])
+	}
+}
+
+// TestRecoveryMiddlewareWritten checks a response already started is left alone and a
+// runtime error is reported from the faulting line.
+func TestRecoveryMiddlewareWritten(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slogTestLogger(t, &buf)
+	var line string
+	handler := RecoveryMiddleware(logger, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		var m map[string]int
+		line = thisLine()
+		m["k"]++
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/jobs", nil))
+	if rec.Code != http.StatusAccepted || rec.Body.Len() != 0 {
+		t.Errorf("response = %d %q", rec.Code, rec.Body.String())
+	}
+	entry := decodeEntry(t, &buf)
+	if msg, _ := entry["message"].(string); !strings.HasPrefix(msg, "[PANIC] assignment to entry in nil map") || entry["error"] == nil {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+	if caller, _ := entry["caller"].(string); !strings.Contains(caller, "recover_test.go:"+line) {
+		t.Errorf("caller = %q, want recover_test.go:%s", caller, line)
+	}
+}
+
+// TestRecoveryMiddlewareRepanic checks Repanic and http.ErrAbortHandler.
+func TestRecoveryMiddlewareRepanic(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slogTestLogger(t, &buf)
+
+	tests := []struct {
+		value  any
+		opts   *RecoveryOptions
+		logged bool
+	}{
+		{"again", &RecoveryOptions{Repanic: true}, true},
+		{http.ErrAbortHandler, nil, false},
+	}
+	for _, tt := range tests {
+		buf.Reset()
+		handler := RecoveryMiddleware(logger, tt.opts)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic(tt.value)
+		}))
+		func() {
+			defer func() {
+				if v := recover(); v != tt.value {
+					t.Errorf("repanic value = %v, want %v", v, tt.value)
+				}
+			}()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		}()
+		if logged := buf.Len() > 0; logged != tt.logged {
+			t.Errorf("%v: logged = %v: %s", tt.value, logged, buf.String())
+		}
+	}
+}
+
+// notifyWriter signals each write on a channel.
+type notifyWriter chan []byte
+
+func (w notifyWriter) Write(p []byte) (int, error) {
+	w <- bytes.Clone(p)
+	return len(p), nil
+}
+
+// TestGo checks a panicking goroutine is logged instead of crashing.
+func TestGo(t *testing.T) {
+	writes := make(notifyWriter, 1)
+	cfg := NewDefaultConfig()
+	cfg.ConsoleWriter = writes
+	cfg.ConsoleFormat = LogFormatJSON
+	logger, err := NewConsoleLogger("info", &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Go(logger.WithFields("worker", "indexer"), func() {
+		panic(errors.New("disk full"))
+	})
+	select {
+	case p := <-writes:
+		entry := decodeEntry(t, bytes.NewBuffer(p))
+		if entry["message"] != "[PANIC] disk full" || entry["error"] != "disk full" || entry["worker"] != "indexer" {
+			t.Errorf("unexpected entry: %v", entry)
+		}
+		if stack, _ := entry["stacktrace"].(string); !strings.HasPrefix(stack, "github.com/K4rian/dslogger.TestGo.func1") {
+			t.Errorf("stacktrace = %q", stack)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("panic not logged")
+	}
+
+	Go(logger, func() { _, _ = io.WriteString(writes, "done") })
+	if p := <-writes; string(p) != "done" {
+		t.Errorf("write = %q", p)
+	}
+}
@@ -0,0 +1,80 @@
+package dslogger
+
+import (
+	"errors"
+	"io"
+	"slices"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// WithSink attaches an additional output core (for example a SyslogSink) that
+// receives every entry written by the logger, next to the console and file outputs.
+// The logger's level gate runs first, the core's own Enabled is consulted afterwards.
+// Sinks implementing io.Closer are closed by Logger.Close.
+func WithSink(core zapcore.Core) Option {
+	return func(l *Logger) error {
+		if core == nil {
+			return errors.New("dslogger: WithSink requires a non-nil core")
+		}
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		l.sinks = append(slices.Clone(l.sinks), core)
+		l.sinkLogger.Store(buildSinkZap(l.config, l.sinks, l.serviceName, l.customFields))
+		return nil
+	}
+}
+
+// serviceCore is implemented by sinks that render the service name natively
+// (for example as the journald SYSLOG_IDENTIFIER) instead of as a "service" field.
+type serviceCore interface {
+	withService(name string) zapcore.Core
+}
+
+// buildSinkZap creates a zap SugaredLogger fanning out to all sink cores, each wrapped
+// for cfg.FieldMerge. Sinks have no service-name decorators, so unless a sink implements
+// serviceCore the service is attached as a structured "service" field, the same way the
+// JSON file path does it.
+func buildSinkZap(cfg *Config, sinks []zapcore.Core, serviceName string, fields []zap.Field, options ...zap.Option) *zap.SugaredLogger {
+	zapOpts := []zap.Option{zap.AddCaller(), zap.AddCallerSkip(dsloggerCallerSkip)}
+	zapOpts = append(zapOpts, options...)
+
+	serviceField := []zap.Field{zap.String("service", serviceName)}
+	cores := make([]zapcore.Core, len(sinks))
+	for i, s := range sinks {
+		switch sc, ok := s.(serviceCore); {
+		case serviceName == "":
+			cores[i] = cfg.wrapCore(s)
+		case ok:
+			cores[i] = cfg.wrapCore(sc.withService(serviceName))
+		default:
+			cores[i] = cfg.wrapCore(s).With(serviceField)
+		}
+	}
+
+	base := zap.New(zapcore.NewTee(cores...), zapOpts...)
+	if len(fields) > 0 {
+		base = base.With(fields...)
+	}
+	return base.Sugar()
+}
+
+// closeSinks closes every sink that implements io.Closer, which must flush its pending
+// entries, syncs the others and joins the errors.
+func closeSinks(sinks []zapcore.Core) error {
+	var errs []error
+	for _, s := range sinks {
+		var err error
+		if c, ok := s.(io.Closer); ok {
+			err = c.Close()
+		} else {
+			err = s.Sync()
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -3,11 +3,19 @@ package dslogger
 import (
 	"context"
 	"log/slog"
+	"runtime"
 	"slices"
+	"strconv"
+	"strings"
 
+	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
+// slogLevelKey carries the exact slog level of records whose level is not one of
+// slog's four named levels, the entry level being the closest zap level below it.
+const slogLevelKey = "slog_level"
+
 // SlogHandler implements [slog.Handler] by delegating to a dslogger [Logger].
 // This allows code written against the stdlib slog API to route through
 // dslogger's console formatting, file rotation, and level management.
@@ -17,69 +25,162 @@ import (
 //	logger, _ := dslogger.NewSimpleConsoleLogger("info")
 //	slogger := slog.New(dslogger.NewSlogHandler(logger))
 //	slogger.Info("message", "key", "value")
+//
+// Entries report the location of the slog call (the record's PC) as their caller.
 type SlogHandler struct {
 	logger *Logger
-	attrs  []slog.Attr
-	group  string
+	opts   SlogHandlerOptions
+	groups []slogGroup // groups[0] is the root, one more per WithGroup
+}
+
+// slogGroup is a group opened by WithGroup and the attributes added to it by WithAttrs.
+type slogGroup struct {
+	name  string
+	attrs []slog.Attr
+}
+
+// SlogHandlerOptions configures a [SlogHandler].
+type SlogHandlerOptions struct {
+	// AddSource adds a "source" field (file:line of the slog call) to every entry, like
+	// slog.HandlerOptions.AddSource. The caller column follows the logger's Config either way.
+	AddSource bool
+
+	// Level filters records below it out before they reach the logger, whose own level
+	// still applies: it can raise the handler's minimum above the logger's, not lower it.
+	// Nil leaves the decision to the logger.
+	Level slog.Leveler
+
+	// ReplaceAttr is called on each non-group attribute and on the source attribute, as with
+	// slog.HandlerOptions.ReplaceAttr. Time, level and message are rendered by the logger's
+	// encoder and are not passed to it.
+	ReplaceAttr func(groups []string, a slog.Attr) slog.Attr
+
+	// NestedGroups renders groups as nested objects ({"http": {"method": "GET"}} in JSON)
+	// instead of dotted keys (http.method).
+	NestedGroups bool
 }
 
 // NewSlogHandler returns a [slog.Handler] backed by the given dslogger Logger.
 func NewSlogHandler(logger *Logger) *SlogHandler {
-	return &SlogHandler{logger: logger}
+	return NewSlogHandlerWithOptions(logger, nil)
+}
+
+// NewSlogHandlerWithOptions returns a [slog.Handler] backed by the given dslogger Logger.
+// A nil opts is the same as the zero SlogHandlerOptions.
+func NewSlogHandlerWithOptions(logger *Logger, opts *SlogHandlerOptions) *SlogHandler {
+	h := &SlogHandler{logger: logger, groups: []slogGroup{{}}}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
 }
 
-// Enabled reports whether the handler handles records at the given level.
+// Slog returns a *slog.Logger writing to l through a SlogHandler with default options.
+func (l *Logger) Slog() *slog.Logger {
+	return slog.New(NewSlogHandler(l))
+}
+
+// Enabled reports whether the handler handles records at the given level, which must
+// pass both SlogHandlerOptions.Level and the logger's level.
 func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	if h.opts.Level != nil && level < h.opts.Level.Level() {
+		return false
+	}
 	return h.logger.level.Enabled(slogToZapLevel(level))
 }
 
 // Handle formats the record and writes it via the underlying dslogger Logger.
-func (h *SlogHandler) Handle(_ context.Context, record slog.Record) error {
-	lvl := slogToZapLevel(record.Level)
+// The fields Logger.WithContext would attach for ctx are added to the entry.
+func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	caller := callerFromPC(record.PC)
 
-	// Estimate total field count: handler attrs + record attrs
-	n := len(h.attrs)*2 + record.NumAttrs()*2
+	// Estimate total field count: source + level + context + attrs
+	n := 2 + 2 + 6 + len(h.groups[0].attrs)*2 + record.NumAttrs()*2
 	fields := make([]any, 0, n)
 
-	for _, a := range h.attrs {
-		fields = appendAttr(fields, h.group, a)
+	if h.opts.AddSource && caller.Defined {
+		src := &slog.Source{Function: caller.Function, File: caller.File, Line: caller.Line}
+		fields = h.appendAttr(fields, nil, slog.Any(slog.SourceKey, src))
+	}
+	if !isNamedSlogLevel(record.Level) {
+		fields = append(fields, slogLevelKey, record.Level.String())
 	}
+	if ctx != nil {
+		fields = h.logger.appendContextFields(fields, ctx)
+	}
+
+	attrs := make([]slog.Attr, 0, record.NumAttrs())
 	record.Attrs(func(a slog.Attr) bool {
-		fields = appendAttr(fields, h.group, a)
+		attrs = append(attrs, a)
 		return true
 	})
+	for _, a := range h.attrTree(attrs) {
+		fields = h.appendAttr(fields, nil, a)
+	}
 
-	h.logger.logMessage(lvl, record.Message, fields...)
+	h.logger.logAt(zapcore.Entry{
+		Level:   slogToZapLevel(record.Level),
+		Time:    record.Time,
+		Message: record.Message,
+		Caller:  caller,
+	}, fields...)
 	return nil
 }
 
+// attrTree returns the handler's attributes followed by the record's, each WithGroup
+// group being a slog.Group holding its own attributes and the groups opened after it.
+// Groups left without attributes are dropped.
+func (h *SlogHandler) attrTree(record []slog.Attr) []slog.Attr {
+	inner := record
+	for i := len(h.groups) - 1; i > 0; i-- {
+		g := h.groups[i]
+		attrs := append(slices.Clip(g.attrs), inner...)
+		inner = nil
+		if !emptyGroup(attrs) {
+			inner = []slog.Attr{{Key: g.name, Value: slog.GroupValue(attrs...)}}
+		}
+	}
+	return append(slices.Clip(h.groups[0].attrs), inner...)
+}
+
 // WithAttrs returns a new handler whose output includes the given attributes.
 func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return &SlogHandler{
-		logger: h.logger,
-		attrs:  append(slices.Clone(h.attrs), attrs...),
-		group:  h.group,
+	if len(attrs) == 0 {
+		return h
 	}
+	groups := slices.Clone(h.groups)
+	last := &groups[len(groups)-1]
+	last.attrs = append(slices.Clip(last.attrs), attrs...)
+	return &SlogHandler{logger: h.logger, opts: h.opts, groups: groups}
 }
 
-// WithGroup returns a new handler that prefixes subsequent attribute keys with the given group name.
+// WithGroup returns a new handler that nests subsequent attributes in the given group,
+// rendered as a key prefix or as a nested object depending on NestedGroups.
 func (h *SlogHandler) WithGroup(name string) slog.Handler {
 	if name == "" {
 		return h
 	}
+	groups := append(slices.Clip(h.groups), slogGroup{name: name})
+	return &SlogHandler{logger: h.logger, opts: h.opts, groups: groups}
+}
 
-	newGroup := name
-	if h.group != "" {
-		newGroup = h.group + "." + name
+// callerFromPC resolves a record PC into a zap caller, undefined for a zero PC.
+func callerFromPC(pc uintptr) zapcore.EntryCaller {
+	if pc == 0 {
+		return zapcore.EntryCaller{}
 	}
-	return &SlogHandler{
-		logger: h.logger,
-		attrs:  slices.Clone(h.attrs),
-		group:  newGroup,
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return zapcore.EntryCaller{
+		Defined:  frame.PC != 0,
+		PC:       pc,
+		File:     frame.File,
+		Line:     frame.Line,
+		Function: frame.Function,
 	}
 }
 
-// slogToZapLevel maps a slog.Level to the closest zapcore.Level.
+// slogToZapLevel maps a slog.Level to the closest zap level at or below it, Debug for
+// anything below Info.
 func slogToZapLevel(l slog.Level) zapcore.Level {
 	switch {
 	case l >= slog.LevelError:
@@ -93,34 +194,109 @@ func slogToZapLevel(l slog.Level) zapcore.Level {
 	}
 }
 
-// appendAttr flattens a slog.Attr into key-value pairs, applying the group prefix.
-// Group-typed attributes are recursively flattened with dot-separated keys.
-func appendAttr(fields []any, group string, a slog.Attr) []any {
-	// Resolve LogValuer chains
-	v := a.Value
-	for {
-		lv, ok := v.Any().(slog.LogValuer)
-		if !ok {
-			break
-		}
-		v = lv.LogValue()
+// isNamedSlogLevel reports whether l is one of slog's Debug, Info, Warn and Error levels.
+func isNamedSlogLevel(l slog.Level) bool {
+	switch l {
+	case slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError:
+		return true
 	}
+	return false
+}
 
-	a = slog.Attr{Key: a.Key, Value: v}
+// resolveAttr resolves a's LogValuer chain and applies ReplaceAttr to non-group attributes.
+// The returned attribute is empty when it must be dropped.
+func (h *SlogHandler) resolveAttr(groups []string, a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	if h.opts.ReplaceAttr != nil && a.Value.Kind() != slog.KindGroup {
+		a = h.opts.ReplaceAttr(groups, a)
+		a.Value = a.Value.Resolve()
+	}
+	return a
+}
+
+// appendAttr appends a as key-value pairs to fields. Groups are flattened with
+// dot-separated keys, or added as a nested object with NestedGroups. groups lists the
+// enclosing group names, as passed to ReplaceAttr.
+func (h *SlogHandler) appendAttr(fields []any, groups []string, a slog.Attr) []any {
+	a = h.resolveAttr(groups, a)
 	if a.Equal(slog.Attr{}) {
 		return fields
 	}
 
 	key := a.Key
-	if group != "" {
-		key = group + "." + key
+	if len(groups) > 0 && !h.opts.NestedGroups {
+		key = strings.Join(groups, ".") + "." + key
 	}
 
 	if a.Value.Kind() == slog.KindGroup {
-		for _, ga := range a.Value.Group() {
-			fields = appendAttr(fields, key, ga)
+		attrs := a.Value.Group()
+		if emptyGroup(attrs) {
+			return fields
+		}
+		if a.Key == "" {
+			// Inline the attributes of a group with an empty key
+			for _, ga := range attrs {
+				fields = h.appendAttr(fields, groups, ga)
+			}
+			return fields
+		}
+		inner := append(slices.Clip(groups), a.Key)
+		if h.opts.NestedGroups {
+			return append(fields, zap.Object(key, slogObject{h: h, groups: inner, attrs: attrs}))
+		}
+		for _, ga := range attrs {
+			fields = h.appendAttr(fields, inner, ga)
 		}
 		return fields
 	}
-	return append(fields, key, a.Value.Any())
+	return append(fields, key, slogValue(a.Value))
+}
+
+// slogObject renders a group as a nested object for NestedGroups.
+type slogObject struct {
+	h      *SlogHandler
+	groups []string
+	attrs  []slog.Attr
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler.
+func (o slogObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	kv := make([]any, 0, len(o.attrs)*2)
+	for _, a := range o.attrs {
+		kv = o.h.appendAttr(kv, o.groups, a)
+	}
+	for i := 0; i < len(kv); i++ {
+		if f, ok := kv[i].(zap.Field); ok {
+			f.AddTo(enc)
+			continue
+		}
+		o.h.logger.config.fieldFor(kv[i].(string), kv[i+1]).AddTo(enc)
+		i++
+	}
+	return nil
+}
+
+// slogValue returns the value logged for a resolved, non-group slog.Value.
+func slogValue(v slog.Value) any {
+	if src, ok := v.Any().(*slog.Source); ok {
+		return src.File + ":" + strconv.Itoa(src.Line)
+	}
+	return v.Any()
+}
+
+// emptyGroup reports whether attrs holds no attribute that would be logged.
+func emptyGroup(attrs []slog.Attr) bool {
+	for _, a := range attrs {
+		v := a.Value.Resolve()
+		if v.Kind() == slog.KindGroup {
+			if !emptyGroup(v.Group()) {
+				return false
+			}
+			continue
+		}
+		if !(a.Key == "" && v.Any() == nil) {
+			return false
+		}
+	}
+	return true
 }
@@ -0,0 +1,353 @@
+package dslogger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/slogtest"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// slogTestLogger returns a JSON console logger writing to buf with the caller enabled.
+func slogTestLogger(t *testing.T, buf *bytes.Buffer) *Logger {
+	t.Helper()
+	cfg := NewDefaultConfig()
+	cfg.ConsoleWriter = buf
+	cfg.ConsoleFormat = LogFormatJSON
+	cfg.ConsoleCaller = CallerConfig{Format: CallerShort, Function: true}
+	logger, err := NewConsoleLogger("debug", &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+// decodeEntry decodes the single JSON entry in buf.
+func decodeEntry(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	return entry
+}
+
+// thisLine returns the line number following the call, where the logging call is.
+func thisLine() string {
+	_, _, line, _ := runtime.Caller(1)
+	return strconv.Itoa(line + 1)
+}
+
+// TestSlogCallerIsRecordPC checks the caller of the package-level slog functions and
+// of a *slog.Logger, which go through slog's internals before reaching the handler.
+func TestSlogCallerIsRecordPC(t *testing.T) {
+	var buf bytes.Buffer
+	slogger := slog.New(NewSlogHandler(slogTestLogger(t, &buf)))
+	prev := slog.Default()
+	slog.SetDefault(slogger)
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		log  func() string
+	}{
+		{"slog.Info", func() string {
+			line := thisLine()
+			slog.Info("msg")
+			return line
+		}},
+		{"slog.InfoContext", func() string {
+			line := thisLine()
+			slog.InfoContext(ctx, "msg")
+			return line
+		}},
+		{"slog.Log", func() string {
+			line := thisLine()
+			slog.Log(ctx, slog.LevelWarn, "msg")
+			return line
+		}},
+		{"Logger.Error", func() string {
+			line := thisLine()
+			slogger.With("k", "v").Error("msg")
+			return line
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			line := tt.log()
+			entry := decodeEntry(t, &buf)
+			if want := "/slog_test.go:" + line; !strings.HasSuffix(entry["caller"].(string), want) {
+				t.Errorf("caller = %q, want suffix %q", entry["caller"], want)
+			}
+			if fn, _ := entry["function"].(string); !strings.HasPrefix(fn, dsloggerPkg+".TestSlogCallerIsRecordPC.func") {
+				t.Errorf("function = %q", fn)
+			}
+		})
+	}
+}
+
+// TestSlogAddSource checks the "source" field and that it is absent by default.
+func TestSlogAddSource(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slogTestLogger(t, &buf)
+
+	slog.New(NewSlogHandler(logger)).Info("plain")
+	if entry := decodeEntry(t, &buf); entry["source"] != nil {
+		t.Errorf("source without AddSource: %v", entry)
+	}
+
+	buf.Reset()
+	slogger := slog.New(NewSlogHandlerWithOptions(logger, &SlogHandlerOptions{AddSource: true}))
+	line := thisLine()
+	slogger.WithGroup("g").Info("sourced", "k", "v")
+	entry := decodeEntry(t, &buf)
+	if src, _ := entry["source"].(string); !strings.HasSuffix(src, "/slog_test.go:"+line) || !strings.HasPrefix(src, "/") {
+		t.Errorf("source = %q, want the full path ending in slog_test.go:%s", src, line)
+	}
+	if entry["g.k"] != "v" {
+		t.Errorf("group attr lost: %v", entry)
+	}
+}
+
+// TestSlogStackStartsAtCaller verifies stacks skip the slog frames above the call.
+func TestSlogStackStartsAtCaller(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := NewDefaultConfig()
+	cfg.ConsoleWriter = &buf
+	cfg.ConsoleFormat = LogFormatJSON
+	cfg.StacktraceLevel = "error"
+	logger, err := NewConsoleLogger("info", &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slog.New(NewSlogHandler(logger)).Error("failed")
+	st, _ := decodeEntry(t, &buf)["stacktrace"].(string)
+	if !strings.HasPrefix(st, dsloggerPkg+".TestSlogStackStartsAtCaller\n\t") {
+		t.Errorf("stacktrace = %q", st)
+	}
+}
+
+// TestSlogHandleContext checks the typed keys and the OTel span context are read from
+// the record's context, and that the context fields stay out of groups.
+func TestSlogHandleContext(t *testing.T) {
+	var buf bytes.Buffer
+	slogger := slog.New(NewSlogHandler(slogTestLogger(t, &buf))).WithGroup("http")
+
+	traceID, _ := trace.TraceIDFromHex("4f9c2a7b1e6d8c3f0a2b4c6d8e1f9a0b")
+	spanID, _ := trace.SpanIDFromHex("1a2b3c4d5e6f7a8b")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+	ctx = context.WithValue(ctx, RequestIDKey, "req-42")
+
+	slogger.InfoContext(ctx, "request", "method", "GET")
+	entry := decodeEntry(t, &buf)
+	if entry["request_id"] != "req-42" || entry["trace_id"] != traceID.String() ||
+		entry["span_id"] != spanID.String() || entry["http.method"] != "GET" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+
+	buf.Reset()
+	slogger.Info("no context")
+	if entry := decodeEntry(t, &buf); entry["request_id"] != nil || entry["trace_id"] != nil {
+		t.Errorf("context fields without a context: %v", entry)
+	}
+}
+
+// TestSlogHandleContextAllocs verifies a context does not cost a derived Logger per record.
+func TestSlogHandleContextAllocs(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.ConsoleWriter = io.Discard
+	cfg.ConsoleFormat = LogFormatJSON
+	logger, err := NewConsoleLogger("info", &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slogger := slog.New(NewSlogHandler(logger))
+	ctx := context.WithValue(context.Background(), RequestIDKey, "req-42")
+
+	plain := testing.AllocsPerRun(100, func() { slogger.Info("msg", "k", 1) })
+	withCtx := testing.AllocsPerRun(100, func() { slogger.InfoContext(ctx, "msg", "k", 1) })
+	if withCtx > plain+2 {
+		t.Errorf("allocs with context = %v, without = %v", withCtx, plain)
+	}
+}
+
+// TestSlogHandlerConformance runs the testing/slogtest suite against the JSON output
+// with nested groups, using slog's key names for the built-in fields.
+func TestSlogHandlerConformance(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := NewDefaultConfig()
+	cfg.ConsoleWriter = &buf
+	cfg.ConsoleFormat = LogFormatJSON
+	cfg.ConsoleConfig = DefaultJSONEncoderConfig
+	cfg.ConsoleConfig.TimeKey = slog.TimeKey
+	cfg.ConsoleConfig.MessageKey = slog.MessageKey
+	logger, err := NewConsoleLogger("info", &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewSlogHandlerWithOptions(logger, &SlogHandlerOptions{NestedGroups: true})
+	err = slogtest.TestHandler(h, func() []map[string]any {
+		var entries []map[string]any
+		for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+			var entry map[string]any
+			if err := json.Unmarshal(line, &entry); err != nil {
+				t.Fatalf("invalid JSON: %v\n%s", err, line)
+			}
+			entries = append(entries, entry)
+		}
+		return entries
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// TestSlogGroupsFlat checks dotted keys keep WithAttrs attributes in the group they were
+// added to, and that groups without attributes are dropped.
+func TestSlogGroupsFlat(t *testing.T) {
+	var buf bytes.Buffer
+	slogger := slog.New(NewSlogHandler(slogTestLogger(t, &buf)))
+
+	slogger.With("a", "b").WithGroup("G").With("c", "d").WithGroup("H").Info("msg", "e", "f",
+		slog.Group("", slog.String("i", "j")), slog.Group("E"))
+	entry := decodeEntry(t, &buf)
+	want := map[string]any{"a": "b", "G.c": "d", "G.H.e": "f", "G.H.i": "j"}
+	for k, v := range want {
+		if entry[k] != v {
+			t.Errorf("%s = %v, want %v in %v", k, entry[k], v, entry)
+		}
+	}
+	if _, ok := entry["G.H.E"]; ok {
+		t.Errorf("empty group logged: %v", entry)
+	}
+
+	buf.Reset()
+	slogger.WithGroup("G").WithGroup("H").Info("no attrs")
+	for k := range decodeEntry(t, &buf) {
+		if strings.HasPrefix(k, "G") {
+			t.Errorf("empty WithGroup logged as %q", k)
+		}
+	}
+}
+
+// TestSlogReplaceAttr checks the group path, dropping, renaming and the source attribute.
+func TestSlogReplaceAttr(t *testing.T) {
+	var buf bytes.Buffer
+	var paths []string
+	h := NewSlogHandlerWithOptions(slogTestLogger(t, &buf), &SlogHandlerOptions{
+		AddSource: true,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			paths = append(paths, strings.Join(append(slices.Clone(groups), a.Key), "/"))
+			switch a.Key {
+			case "password":
+				return slog.Attr{}
+			case slog.SourceKey:
+				src := a.Value.Any().(*slog.Source)
+				return slog.String("src", filepath.Base(src.File))
+			}
+			a.Key = strings.ToUpper(a.Key)
+			return a
+		},
+	})
+	slog.New(h).WithGroup("req").Info("login", "user", "bob", "password", "hunter2",
+		slog.Group("net", slog.String("ip", "10.0.0.1")))
+
+	entry := decodeEntry(t, &buf)
+	if entry["req.USER"] != "bob" || entry["req.net.IP"] != "10.0.0.1" || entry["src"] != "slog_test.go" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+	if _, ok := entry["req.password"]; ok {
+		t.Errorf("dropped attribute logged: %v", entry)
+	}
+	want := []string{"source", "req/user", "req/password", "req/net/ip"}
+	if !slices.Equal(paths, want) {
+		t.Errorf("ReplaceAttr paths = %v, want %v", paths, want)
+	}
+}
+
+// TestSlogHandlerLevel checks the handler level and the exact level of custom slog levels.
+func TestSlogHandlerLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slogTestLogger(t, &buf)
+	level := new(slog.LevelVar)
+	level.Set(slog.LevelWarn)
+	slogger := slog.New(NewSlogHandlerWithOptions(logger, &SlogHandlerOptions{Level: level}))
+
+	slogger.Info("dropped")
+	if buf.Len() != 0 {
+		t.Fatalf("record below the handler level: %q", buf.String())
+	}
+
+	slogger.Log(context.Background(), slog.LevelWarn+2, "notice")
+	entry := decodeEntry(t, &buf)
+	if lvl, _ := entry["level"].(string); strings.TrimSpace(lvl) != "WARN" || entry[slogLevelKey] != "WARN+2" {
+		t.Errorf("unexpected level fields: %v", entry)
+	}
+
+	buf.Reset()
+	level.Set(slog.LevelDebug)
+	slogger.Error("named")
+	if entry := decodeEntry(t, &buf); entry[slogLevelKey] != nil {
+		t.Errorf("%s set for a named level: %v", slogLevelKey, entry)
+	}
+
+	// The handler level narrows the logger's, it cannot widen it
+	buf.Reset()
+	if err := logger.SetLogLevel("info"); err != nil {
+		t.Fatal(err)
+	}
+	slogger.Debug("dropped")
+	if buf.Len() != 0 || slogger.Enabled(context.Background(), slog.LevelDebug) {
+		t.Errorf("record below the logger level: %q", buf.String())
+	}
+}
+
+// TestSlogNestedGroupsText checks nested groups in the text format.
+func TestSlogNestedGroupsText(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := NewDefaultConfig()
+	cfg.ConsoleWriter = &buf
+	cfg.NoColor = true
+	logger, err := NewConsoleLogger("info", &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slog.New(NewSlogHandlerWithOptions(logger, &SlogHandlerOptions{NestedGroups: true})).
+		WithGroup("http").Info("request", "method", "GET", slog.Group("peer", "ip", "10.0.0.1"))
+	if !strings.HasSuffix(buf.String(), "request | http: {method=GET, peer={ip=10.0.0.1}}\n") {
+		t.Errorf("unexpected output: %q", buf.String())
+	}
+}
+
+// TestSlogNestedGroupThenError checks an error attribute after a nested group keeps its
+// key and the rich error rendering.
+func TestSlogNestedGroupThenError(t *testing.T) {
+	var buf bytes.Buffer
+	slogger := slog.New(NewSlogHandlerWithOptions(slogTestLogger(t, &buf), &SlogHandlerOptions{NestedGroups: true}))
+
+	slogger.Error("request failed", slog.Group("req", "id", 7), "err", fmt.Errorf("query: %w", io.EOF), "user", "u-1")
+	entry := decodeEntry(t, &buf)
+	if req, _ := entry["req"].(map[string]any); req["id"] != float64(7) {
+		t.Errorf("req = %v", entry["req"])
+	}
+	if entry["err"] != "query: EOF" || fmt.Sprint(entry["errCauses"]) != "[EOF]" || entry["user"] != "u-1" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+}
@@ -0,0 +1,166 @@
+package dslogger
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// spanMarker carries the recording span of a context among the fields of a log call, like
+// ForceStack it takes a single slot and is removed before the fields are normalized.
+type spanMarker struct {
+	span trace.Span
+}
+
+// spanRecording reports whether entries may be recorded on spans at all.
+func (c *Config) spanRecording() bool {
+	return c.spanEventEnabled || c.spanStatusEnabled
+}
+
+// spanEnabled reports whether entries at lvl are recorded on spans.
+func (c *Config) spanEnabled(lvl zapcore.Level) bool {
+	return (c.spanEventEnabled && lvl >= c.spanEventLevel) ||
+		(c.spanStatusEnabled && lvl >= c.spanStatusLevel)
+}
+
+// recordingSpan returns span when it is recording and entries may be recorded on spans,
+// nil otherwise.
+func (c *Config) recordingSpan(span trace.Span) trace.Span {
+	if !c.spanRecording() || span == nil || !span.IsRecording() {
+		return nil
+	}
+	return span
+}
+
+// takeSpan removes the span markers from fields and returns the span of the last one.
+func takeSpan(fields []any) ([]any, trace.Span) {
+	i := 0
+	for ; i < len(fields); i++ {
+		if _, ok := fields[i].(spanMarker); ok {
+			break
+		}
+	}
+	if i == len(fields) {
+		return fields, nil
+	}
+	var span trace.Span
+	out := make([]any, 0, len(fields)-1)
+	for _, f := range fields {
+		if m, ok := f.(spanMarker); ok {
+			span = m.span
+			continue
+		}
+		out = append(out, f)
+	}
+	return out, span
+}
+
+// takeEntrySpan removes the span markers from fields and returns the span to record the
+// entry on: the span of the call's context, else the one of the WithContext context.
+func (l *Logger) takeEntrySpan(fields []any) ([]any, trace.Span) {
+	if !l.config.spanRecording() {
+		return fields, nil
+	}
+	fields, span := takeSpan(fields)
+	if span == nil {
+		span = l.span
+	}
+	return fields, span
+}
+
+// recordSpan adds ent to span as an event carrying the level, service name, logger fields
+// and fields as attributes, and sets the span status to Error, as configured for the
+// entry's level.
+func (l *Logger) recordSpan(span trace.Span, ent zapcore.Entry, fields []zap.Field) {
+	if !span.IsRecording() {
+		return
+	}
+	cfg := l.config
+	if cfg.spanEventEnabled && ent.Level >= cfg.spanEventLevel {
+		attrs := make([]attribute.KeyValue, 0, len(l.customFields)+len(fields)+2)
+		attrs = append(attrs, attribute.String("level", ent.Level.CapitalString()))
+		if l.serviceName != "" {
+			attrs = append(attrs, attribute.String("service", l.serviceName))
+		}
+		attrs = appendSpanAttributes(attrs, l.customFields)
+		attrs = appendSpanAttributes(attrs, fields)
+		span.AddEvent(ent.Message, trace.WithTimestamp(ent.Time), trace.WithAttributes(attrs...))
+	}
+	if cfg.spanStatusEnabled && ent.Level >= cfg.spanStatusLevel {
+		span.SetStatus(codes.Error, ent.Message)
+	}
+}
+
+// appendSpanAttributes appends fields to attrs as span attributes. The trace and span IDs,
+// which the span already carries, stacks and namespaces are left out, errors become their
+// message and nested values their JSON rendering.
+func appendSpanAttributes(attrs []attribute.KeyValue, fields []zap.Field) []attribute.KeyValue {
+	for _, f := range fields {
+		switch {
+		case f.Type == zapcore.InlineMarshalerType:
+			if ef, ok := f.Interface.(errorField); ok {
+				attrs = append(attrs, attribute.String(ef.key, ef.err.Error()))
+			}
+		case f.Type == zapcore.NamespaceType, f.Type == zapcore.SkipType,
+			f.Key == string(TraceIDKey), f.Key == string(SpanIDKey):
+		default:
+			attrs = append(attrs, spanAttribute(f))
+		}
+	}
+	return attrs
+}
+
+// spanAttribute converts a zap field to a span attribute.
+func spanAttribute(f zap.Field) attribute.KeyValue {
+	switch f.Type {
+	case zapcore.ArrayMarshalerType, zapcore.ObjectMarshalerType, zapcore.ReflectType:
+		enc := zapcore.NewMapObjectEncoder()
+		f.AddTo(enc)
+		if b, err := json.Marshal(enc.Fields[f.Key]); err == nil {
+			return attribute.String(f.Key, string(b))
+		}
+	}
+
+	switch v := zapFieldValue(f).(type) {
+	case string:
+		return attribute.String(f.Key, v)
+	case bool:
+		return attribute.Bool(f.Key, v)
+	case int64:
+		return attribute.Int64(f.Key, v)
+	case int32:
+		return attribute.Int64(f.Key, int64(v))
+	case int16:
+		return attribute.Int64(f.Key, int64(v))
+	case int8:
+		return attribute.Int64(f.Key, int64(v))
+	case uint64:
+		if v <= math.MaxInt64 {
+			return attribute.Int64(f.Key, int64(v))
+		}
+	case uint32:
+		return attribute.Int64(f.Key, int64(v))
+	case uint16:
+		return attribute.Int64(f.Key, int64(v))
+	case uint8:
+		return attribute.Int64(f.Key, int64(v))
+	case float64:
+		return attribute.Float64(f.Key, v)
+	case float32:
+		return attribute.Float64(f.Key, float64(v))
+	case time.Time:
+		return attribute.String(f.Key, v.Format(time.RFC3339Nano))
+	case error:
+		return attribute.String(f.Key, v.Error())
+	case fmt.Stringer:
+		return attribute.String(f.Key, v.String())
+	}
+	return attribute.String(f.Key, fmt.Sprint(zapFieldValue(f)))
+}
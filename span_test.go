@@ -0,0 +1,144 @@
+package dslogger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap/zapcore"
+)
+
+// spanEvent is an event added to a recordingSpan.
+type spanEvent struct {
+	Name       string
+	Attributes []attribute.KeyValue
+}
+
+// recordingSpan is a trace.Span recording its events and status until it is ended.
+type recordingSpan struct {
+	noop.Span
+	sc     trace.SpanContext
+	ended  bool
+	events []spanEvent
+	code   codes.Code
+	desc   string
+}
+
+// startRecordingSpan returns ctx carrying a new sampled recordingSpan.
+func startRecordingSpan(ctx context.Context) (context.Context, *recordingSpan) {
+	span := &recordingSpan{sc: trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})}
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+func (s *recordingSpan) SpanContext() trace.SpanContext { return s.sc }
+func (s *recordingSpan) IsRecording() bool              { return !s.ended }
+func (s *recordingSpan) End(...trace.SpanEndOption)     { s.ended = true }
+
+func (s *recordingSpan) AddEvent(name string, opts ...trace.EventOption) {
+	cfg := trace.NewEventConfig(opts...)
+	s.events = append(s.events, spanEvent{Name: name, Attributes: cfg.Attributes()})
+}
+
+func (s *recordingSpan) SetStatus(code codes.Code, desc string) {
+	s.code, s.desc = code, desc
+}
+
+// spanTestLogger returns a JSON console logger recording Warn entries as span events and
+// setting the span status on Error entries.
+func spanTestLogger(t *testing.T, buf *bytes.Buffer) *Logger {
+	t.Helper()
+	cfg := NewDefaultConfig()
+	cfg.ConsoleWriter = buf
+	cfg.ConsoleFormat = LogFormatJSON
+	cfg.SpanEventLevel = "warn"
+	cfg.SpanStatusLevel = "error"
+	logger, err := NewConsoleLogger("debug", &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+// eventAttrs returns the attributes of event as a map.
+func eventAttrs(event spanEvent) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value, len(event.Attributes))
+	for _, kv := range event.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+// TestSpanEvents checks the levels recorded as events, their attributes and the status
+// set by Error entries, through WithContext, the Context methods and the slog bridge.
+func TestSpanEvents(t *testing.T) {
+	var buf bytes.Buffer
+	logger := spanTestLogger(t, &buf)
+	ctx, span := startRecordingSpan(context.Background())
+
+	derived := logger.WithService("api").WithFields("tenant", "acme").WithContext(ctx)
+	derived.Info("not recorded")
+	derived.Warn("slow query", "ms", 950, "tags", []string{"db"})
+	logger.ErrorContext(ctx, "query failed", "error", errors.New("timeout"))
+	slog.New(NewSlogHandler(logger)).WarnContext(ctx, "bridged", "attempt", 2)
+	span.End()
+	derived.Error("after end")
+
+	events := span.events
+	if len(events) != 3 {
+		t.Fatalf("events = %v", events)
+	}
+
+	warn := eventAttrs(events[0])
+	if events[0].Name != "slow query" || warn["level"].AsString() != "WARN" || warn["service"].AsString() != "api" ||
+		warn["tenant"].AsString() != "acme" || warn["ms"].AsInt64() != 950 || warn["tags"].AsString() != `["db"]` {
+		t.Errorf("warn event = %s %v", events[0].Name, events[0].Attributes)
+	}
+	if _, ok := warn[attribute.Key(TraceIDKey)]; ok {
+		t.Errorf("trace_id attribute: %v", events[0].Attributes)
+	}
+	if attrs := eventAttrs(events[1]); events[1].Name != "query failed" || attrs["error"].AsString() != "timeout" {
+		t.Errorf("error event = %s %v", events[1].Name, events[1].Attributes)
+	}
+	if attrs := eventAttrs(events[2]); events[2].Name != "bridged" || attrs["attempt"].AsInt64() != 2 {
+		t.Errorf("slog event = %s %v", events[2].Name, events[2].Attributes)
+	}
+	if span.code != codes.Error || span.desc != "query failed" {
+		t.Errorf("status = %v %q", span.code, span.desc)
+	}
+
+	// The span fields are still logged, the marker is not.
+	buf.Reset()
+	logger.WarnContext(ctx, "logged", "k", "v")
+	if entry := decodeEntry(t, &buf); entry["k"] != "v" || entry[string(SpanIDKey)] == nil {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+}
+
+// TestSpanEventsDisabled checks spans are left alone without SpanEventLevel and
+// SpanStatusLevel, and with a level below them.
+func TestSpanEventsDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slogTestLogger(t, &buf)
+	ctx, span := startRecordingSpan(context.Background())
+
+	logger.WithContext(ctx).Error("failed")
+	logger.ErrorContext(ctx, "failed")
+	span.End()
+	if len(span.events) != 0 || span.code != codes.Unset {
+		t.Errorf("events = %v, status = %v", span.events, span.code)
+	}
+
+	if logger.config.spanEnabled(zapcore.ErrorLevel) {
+		t.Error("span recording enabled by default")
+	}
+}
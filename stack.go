@@ -0,0 +1,249 @@
+package dslogger
+
+import (
+	"reflect"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultStacktraceMaxDepth is the frame limit used when Config.StacktraceMaxDepth is zero.
+const defaultStacktraceMaxDepth = 32
+
+// sinkStacktraceKey is the key of captured stacks for sinks, which encode entries with
+// their own configuration. The console and file outputs use the StacktraceKey of their
+// encoder config instead.
+const sinkStacktraceKey = "stacktrace"
+
+// forceStackMarker is the type of ForceStack.
+type forceStackMarker struct{}
+
+// ForceStack, passed among the fields of a log call, attaches a stack trace to that
+// entry regardless of Config.StacktraceLevel. It takes a single argument slot:
+//
+//	logger.Warn("slow query", dslogger.ForceStack, "ms", 950)
+var ForceStack = forceStackMarker{}
+
+// dsloggerPkg is this package's import path, used to trim its frames from stacks.
+var dsloggerPkg = reflect.TypeFor[stackField]().PkgPath()
+
+// stackCarrier is implemented by the inline fields that carry a stack trace. Console
+// encoders print those stacks below the line instead of as a field.
+type stackCarrier interface {
+	stackFrames() []runtime.Frame
+}
+
+// stackField is the field a captured stack is logged as. Structured encoders get it as a
+// string under key, set per output by stackKeyed, console encoders print it below the line.
+type stackField struct {
+	frames []runtime.Frame
+	key    string
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler.
+func (s stackField) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	switch enc.(type) {
+	case *dsConsoleEncoder, *dsPrettyEncoder, *treeObjectEncoder:
+		return nil
+	}
+	if s.key != "" {
+		enc.AddString(s.key, formatFrames(s.frames))
+	}
+	return nil
+}
+
+// stackKeyed returns fields with their stackField rendered under key, the StacktraceKey
+// of the output's encoder config, an empty key leaving the stack out of structured
+// encoders. fields is copied, not modified, and returned as is when it holds no stack.
+func stackKeyed(fields []any, key string) []any {
+	for i, f := range fields {
+		zf, ok := f.(zapcore.Field)
+		if !ok {
+			continue
+		}
+		if sf, ok := zf.Interface.(stackField); ok {
+			sf.key = key
+			out := slices.Clone(fields)
+			out[i] = zapcore.Field{Type: zapcore.InlineMarshalerType, Interface: sf}
+			return out
+		}
+	}
+	return fields
+}
+
+func (s stackField) stackFrames() []runtime.Frame {
+	return s.frames
+}
+
+// takeForceStack removes ForceStack markers from fields and reports whether there was one.
+func takeForceStack(fields []any) ([]any, bool) {
+	i := 0
+	for ; i < len(fields); i++ {
+		if _, ok := fields[i].(forceStackMarker); ok {
+			break
+		}
+	}
+	if i == len(fields) {
+		return fields, false
+	}
+	out := make([]any, 0, len(fields)-1)
+	for _, f := range fields {
+		if _, ok := f.(forceStackMarker); !ok {
+			out = append(out, f)
+		}
+	}
+	return out, true
+}
+
+// hasStackField reports whether fields already carry a captured stack, such as the stack
+// of a recovered panic.
+func hasStackField(fields []any) bool {
+	return slices.ContainsFunc(fields, func(f any) bool {
+		zf, ok := f.(zapcore.Field)
+		if !ok || zf.Type != zapcore.InlineMarshalerType {
+			return false
+		}
+		_, ok = zf.Interface.(stackField)
+		return ok
+	})
+}
+
+// stackEnabled reports whether entries at lvl get a stack trace per Config.StacktraceLevel.
+func (c *Config) stackEnabled(lvl zapcore.Level) bool {
+	return c.stacktraceEnabled && lvl >= c.stacktraceLevel
+}
+
+// stackDepth returns the configured frame limit.
+func (c *Config) stackDepth() int {
+	if c.StacktraceMaxDepth > 0 {
+		return c.StacktraceMaxDepth
+	}
+	return defaultStacktraceMaxDepth
+}
+
+// captureStack returns the calling goroutine's stack, skip frames above its caller,
+// trimmed by trimFrames to at most maxDepth frames.
+func captureStack(skip, maxDepth int) []runtime.Frame {
+	pcs := make([]uintptr, maxDepth+16) // headroom for the frames trimmed below
+	n := runtime.Callers(skip+2, pcs)
+	return trimFrames(pcs[:n], maxDepth)
+}
+
+// captureStackFrom returns the calling goroutine's stack starting at the frame of pc, a
+// return address as recorded by runtime.Callers (slog.Record.PC for instance). Frames
+// above it are dropped; when pc is not on the stack the whole stack is kept.
+func captureStackFrom(pc uintptr, maxDepth int) []runtime.Frame {
+	pcs := make([]uintptr, maxDepth+64) // headroom for the bridge frames above pc
+	n := runtime.Callers(2, pcs)
+	pcs = pcs[:n]
+	if i := slices.Index(pcs, pc); i >= 0 {
+		pcs = pcs[i:]
+	}
+	return trimFrames(pcs, maxDepth)
+}
+
+// trimFrames resolves pcs, dropping zap frames and dslogger's own frames (test files
+// excepted) as well as the runtime's goroutine entry, and keeps at most maxDepth frames.
+func trimFrames(pcs []uintptr, maxDepth int) []runtime.Frame {
+	var out []runtime.Frame
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if keepFrame(frame) {
+			out = append(out, frame)
+			if len(out) == maxDepth {
+				break
+			}
+		}
+		if !more {
+			break
+		}
+	}
+	return out
+}
+
+func keepFrame(frame runtime.Frame) bool {
+	fn := frame.Function
+	switch {
+	case fn == "" || fn == "runtime.goexit":
+		return false
+	case strings.HasPrefix(fn, "go.uber.org/zap"):
+		return false
+	case strings.HasPrefix(fn, dsloggerPkg+".") || strings.HasPrefix(fn, dsloggerPkg+"/"):
+		return strings.HasSuffix(frame.File, "_test.go")
+	}
+	return true
+}
+
+// formatFrames renders frames like zap's entry stacks, two lines per frame:
+//
+//	function
+//		file:line
+func formatFrames(frames []runtime.Frame) string {
+	var b strings.Builder
+	for i, f := range frames {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(f.Function)
+		b.WriteString("\n\t")
+		b.WriteString(f.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(f.Line))
+	}
+	return b.String()
+}
+
+// formatFramesCompact renders frames one per line for the text format:
+//
+//	at function (file:line)
+func formatFramesCompact(frames []runtime.Frame) string {
+	var b strings.Builder
+	for i, f := range frames {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString("    at ")
+		b.WriteString(f.Function)
+		b.WriteString(" (")
+		b.WriteString(f.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(f.Line))
+		b.WriteByte(')')
+	}
+	return b.String()
+}
+
+// fieldStack returns the frames carried by f when it is a stack-carrying inline field.
+func fieldStack(f zapcore.Field) []runtime.Frame {
+	if f.Type != zapcore.InlineMarshalerType {
+		return nil
+	}
+	if sc, ok := f.Interface.(stackCarrier); ok {
+		return sc.stackFrames()
+	}
+	return nil
+}
+
+// sinkStackPairs returns the stacks carried by fields keyed as the JSON encoder keys them,
+// a captured stack under sinkStacktraceKey and an error's under its key plus "Stack". The
+// syslog and journald sinks encode fields with a dsConsoleEncoder, which leaves stacks
+// out, and add these pairs instead.
+func sinkStackPairs(fields []zapcore.Field) []kvPair {
+	var pairs []kvPair
+	for _, f := range fields {
+		frames := fieldStack(f)
+		if len(frames) == 0 {
+			continue
+		}
+		key := sinkStacktraceKey
+		if ef, ok := f.Interface.(errorField); ok {
+			key = ef.key + "Stack"
+		}
+		pairs = append(pairs, kvPair{key: key, val: formatFrames(frames)})
+	}
+	return pairs
+}
@@ -0,0 +1,161 @@
+package dslogger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// stackTestLogger returns a console logger writing to buf with the given stack settings.
+func stackTestLogger(t *testing.T, buf *bytes.Buffer, format LogFormat, level string, depth int) *Logger {
+	t.Helper()
+	cfg := NewDefaultConfig()
+	cfg.ConsoleWriter = buf
+	cfg.ConsoleFormat = format
+	cfg.NoColor = true
+	cfg.StacktraceLevel = level
+	cfg.StacktraceMaxDepth = depth
+	logger, err := NewConsoleLogger("debug", &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+// TestStacktraceLevelText checks the level threshold, the compact text format and trimming.
+func TestStacktraceLevelText(t *testing.T) {
+	var buf bytes.Buffer
+	logger := stackTestLogger(t, &buf, LogFormatText, "warn", 0)
+
+	logger.Info("no stack")
+	if strings.Contains(buf.String(), "    at ") {
+		t.Fatalf("stack below StacktraceLevel: %q", buf.String())
+	}
+
+	buf.Reset()
+	logger.Error("with stack", "k", "v")
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if !strings.HasSuffix(lines[0], "with stack | k: v") {
+		t.Errorf("unexpected first line: %q", lines[0])
+	}
+	if len(lines) < 2 || !strings.HasPrefix(lines[1], "    at "+dsloggerPkg+".TestStacktraceLevelText (") ||
+		!strings.Contains(lines[1], "stack_test.go:") {
+		t.Fatalf("first frame is not the calling test: %q", lines)
+	}
+	for _, l := range lines[1:] {
+		if strings.Contains(l, "go.uber.org/zap") || strings.Contains(l, "logger.go") {
+			t.Errorf("untrimmed frame: %q", l)
+		}
+	}
+}
+
+// TestStacktraceJSON checks the "stacktrace" string field.
+func TestStacktraceJSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger := stackTestLogger(t, &buf, LogFormatJSON, "error", 0)
+	logger.Err(errors.New("boom"), "failed")
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	st, _ := entry["stacktrace"].(string)
+	if !strings.HasPrefix(st, dsloggerPkg+".TestStacktraceJSON\n\t") {
+		t.Errorf("stacktrace = %q", st)
+	}
+}
+
+// TestStacktraceKeyFromConfig checks the JSON key follows the encoder config's
+// StacktraceKey, an empty key leaving the stack out.
+func TestStacktraceKeyFromConfig(t *testing.T) {
+	for _, key := range []string{"stack", ""} {
+		var buf bytes.Buffer
+		cfg := NewDefaultConfig()
+		cfg.ConsoleWriter = &buf
+		cfg.ConsoleFormat = LogFormatJSON
+		cfg.ConsoleConfig.StacktraceKey = key
+		cfg.StacktraceLevel = "error"
+		logger, err := NewConsoleLogger("info", &cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger.Error("failed")
+
+		var entry map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+		}
+		if _, ok := entry["stacktrace"]; ok {
+			t.Errorf("key %q: stack logged as stacktrace: %v", key, entry)
+		}
+		if st, _ := entry[key].(string); key != "" && !strings.HasPrefix(st, dsloggerPkg+".TestStacktraceKeyFromConfig\n\t") {
+			t.Errorf("key %q: stack = %q", key, st)
+		}
+	}
+}
+
+// TestForceStack verifies ForceStack attaches a stack without consuming a field pair.
+func TestForceStack(t *testing.T) {
+	var buf bytes.Buffer
+	logger := stackTestLogger(t, &buf, LogFormatText, "", 0)
+	logger.Info("forced", ForceStack, "k", "v")
+
+	out := buf.String()
+	if !strings.Contains(out, "forced | k: v\n") || strings.Contains(out, "<missing>") {
+		t.Errorf("ForceStack disturbed the fields: %q", out)
+	}
+	if !strings.Contains(out, "    at "+dsloggerPkg+".TestForceStack (") {
+		t.Errorf("forced stack missing: %q", out)
+	}
+}
+
+// TestForceStackContext verifies ForceStack through the *Context methods, whose context
+// fields are prepended before the marker is taken out.
+func TestForceStackContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger := stackTestLogger(t, &buf, LogFormatText, "", 0)
+	ctx := context.WithValue(context.Background(), RequestIDKey, "r-1")
+	logger.InfoContext(ctx, "forced", ForceStack, "k", "v")
+
+	out := buf.String()
+	if !strings.Contains(out, "forced | request_id: r-1 | k: v\n") || strings.Contains(out, "<missing>") {
+		t.Errorf("ForceStack disturbed the fields: %q", out)
+	}
+	if !strings.Contains(out, "    at "+dsloggerPkg+".TestForceStackContext (") {
+		t.Errorf("forced stack missing: %q", out)
+	}
+}
+
+// TestStacktraceMaxDepth verifies the frame limit.
+func TestStacktraceMaxDepth(t *testing.T) {
+	var buf bytes.Buffer
+	logger := stackTestLogger(t, &buf, LogFormatText, "debug", 2)
+
+	var recurse func(n int)
+	recurse = func(n int) {
+		if n == 0 {
+			logger.Debug("deep")
+			return
+		}
+		recurse(n - 1)
+	}
+	recurse(5)
+
+	if n := strings.Count(buf.String(), "    at "); n != 2 {
+		t.Errorf("frames = %d, want 2:\n%s", n, buf.String())
+	}
+}
+
+// TestStacktraceInvalidLevel verifies an invalid StacktraceLevel disables stacks
+// instead of failing construction.
+func TestStacktraceInvalidLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := stackTestLogger(t, &buf, LogFormatText, "loud", 0)
+	logger.Error("plain")
+	if strings.Contains(buf.String(), "    at ") {
+		t.Errorf("stack with an invalid StacktraceLevel: %q", buf.String())
+	}
+}
@@ -0,0 +1,91 @@
+package dslogger
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"runtime"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// stdLogWriter is the io.Writer behind the standard loggers returned by StdLog.
+// Each Write is one entry, logged at level with the log call as its caller.
+type stdLogWriter struct {
+	logger *Logger
+	level  zapcore.Level
+}
+
+// Write implements io.Writer.
+func (w *stdLogWriter) Write(p []byte) (int, error) {
+	if !w.logger.level.Enabled(w.level) {
+		return len(p), nil
+	}
+	msg := string(bytes.TrimSuffix(p, []byte{'\n'}))
+	w.logger.logAt(zapcore.Entry{
+		Level:   w.level,
+		Time:    time.Now(),
+		Message: msg,
+		Caller:  stdLogCaller(),
+	})
+	return len(p), nil
+}
+
+// stdLogCaller returns the first frame above stdLogWriter.Write outside the log package,
+// that is the caller of log.Printf, (*log.Logger).Println and friends.
+func stdLogCaller() zapcore.EntryCaller {
+	var pcs [8]uintptr
+	n := runtime.Callers(3, pcs[:]) // skip runtime.Callers, stdLogCaller and Write
+	for _, pc := range pcs[:n] {
+		caller := callerFromPC(pc)
+		if !strings.HasPrefix(caller.Function, "log.") {
+			return caller
+		}
+	}
+	return zapcore.EntryCaller{}
+}
+
+// StdLog returns a standard library *log.Logger writing to l at the given level, for
+// APIs that take one such as http.Server.ErrorLog. Every line is one entry, reported
+// from the line's log call.
+func (l *Logger) StdLog(level string) (*log.Logger, error) {
+	lvl, err := parseLogLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	return log.New(&stdLogWriter{logger: l, level: lvl}, "", 0), nil
+}
+
+// RedirectStdLog routes the output of the log package's default logger to l at Info
+// level, with no prefix or flags of its own. It returns a function restoring the previous
+// output, prefix and flags, handy in tests:
+//
+//	defer logger.RedirectStdLog()()
+func (l *Logger) RedirectStdLog() func() {
+	w, prefix, flags := log.Writer(), log.Prefix(), log.Flags()
+	log.SetOutput(&stdLogWriter{logger: l, level: zapcore.InfoLevel})
+	log.SetPrefix("")
+	log.SetFlags(0)
+	return func() {
+		log.SetOutput(w)
+		log.SetPrefix(prefix)
+		log.SetFlags(flags)
+	}
+}
+
+// SetAsSlogDefault makes l.Slog() the slog default logger and redirects the log package
+// as RedirectStdLog does, so slog.Info and log.Printf both end up in l with their own
+// call site as caller. It returns a function restoring the previous defaults.
+func (l *Logger) SetAsSlogDefault() func() {
+	prev := slog.Default()
+	restoreLog := l.RedirectStdLog()
+	slog.SetDefault(l.Slog())
+	// slog.SetDefault routes the log package to the handler without caller information
+	log.SetOutput(&stdLogWriter{logger: l, level: zapcore.InfoLevel})
+	return func() {
+		slog.SetDefault(prev)
+		restoreLog()
+	}
+}
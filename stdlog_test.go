@@ -0,0 +1,107 @@
+package dslogger
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// assertEntry checks the JSON entry in buf has the level, message and a caller on line.
+func assertEntry(t *testing.T, buf *bytes.Buffer, level, msg, line string) {
+	t.Helper()
+	entry := decodeEntry(t, buf)
+	if lvl, _ := entry["level"].(string); strings.TrimSpace(lvl) != level || entry["message"] != msg {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+	if caller, _ := entry["caller"].(string); !strings.HasSuffix(caller, "/stdlog_test.go:"+line) {
+		t.Errorf("caller = %q, want stdlog_test.go:%s", caller, line)
+	}
+}
+
+// TestStdLog checks level, message and caller of the *log.Logger adapter.
+func TestStdLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slogTestLogger(t, &buf)
+
+	std, err := logger.StdLog("warn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := thisLine()
+	std.Printf("disk %d%% full", 91)
+	assertEntry(t, &buf, "WARN", "disk 91% full", line)
+
+	buf.Reset()
+	line = thisLine()
+	std.Println("second")
+	assertEntry(t, &buf, "WARN", "second", line)
+
+	if _, err := logger.StdLog("loud"); err == nil {
+		t.Error("StdLog accepted an invalid level")
+	}
+}
+
+// TestRedirectStdLog checks the log package defaults are routed and restored.
+func TestRedirectStdLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slogTestLogger(t, &buf)
+
+	w, flags := log.Writer(), log.Flags()
+	t.Cleanup(func() {
+		log.SetOutput(w)
+		log.SetPrefix("")
+		log.SetFlags(flags)
+	})
+	var orig bytes.Buffer
+	log.SetOutput(&orig)
+	log.SetPrefix("app: ")
+
+	restore := logger.RedirectStdLog()
+	line := thisLine()
+	log.Printf("redirected %s", "line")
+	assertEntry(t, &buf, "INFO", "redirected line", line)
+
+	restore()
+	log.Print("restored")
+	if orig.String() == "" || !strings.Contains(orig.String(), "app: ") || strings.Contains(buf.String(), "restored") {
+		t.Errorf("log package not restored: %q", orig.String())
+	}
+}
+
+// TestSetAsSlogDefault checks slog and log both reach the logger with their own caller.
+func TestSetAsSlogDefault(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slogTestLogger(t, &buf)
+	prev := slog.Default()
+
+	restore := logger.SetAsSlogDefault()
+	line := thisLine()
+	slog.Warn("from slog", "k", "v")
+	assertEntry(t, &buf, "WARN", "from slog", line)
+
+	buf.Reset()
+	line = thisLine()
+	log.Print("from log")
+	assertEntry(t, &buf, "INFO", "from log", line)
+
+	buf.Reset()
+	line = thisLine()
+	logger.Slog().Info("from Slog")
+	assertEntry(t, &buf, "INFO", "from Slog", line)
+
+	restore()
+	if slog.Default() != prev {
+		t.Error("slog default not restored")
+	}
+	buf.Reset()
+	w := log.Writer()
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(w)
+	slog.Info("after restore")
+	if buf.Len() != 0 {
+		t.Errorf("entry after restore: %q", buf.String())
+	}
+}
@@ -0,0 +1,499 @@
+package dslogger
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// SyslogFacility is a syslog facility code (RFC 5424 section 6.2.1).
+type SyslogFacility int
+
+// Syslog facilities.
+const (
+	FacilityKern SyslogFacility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLPR
+	FacilityNews
+	FacilityUUCP
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFTP
+	FacilityNTP
+	FacilityAudit
+	FacilityAlert
+	FacilityClock
+	FacilityLocal0
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+// SyslogSeverity is a syslog severity code (RFC 5424 section 6.2.1).
+type SyslogSeverity int
+
+// Syslog severities, from most to least severe.
+const (
+	SeverityEmergency SyslogSeverity = iota
+	SeverityAlert
+	SeverityCritical
+	SeverityError
+	SeverityWarning
+	SeverityNotice
+	SeverityInfo
+	SeverityDebug
+)
+
+// SyslogFormat selects the syslog message layout.
+type SyslogFormat string
+
+// Supported syslog message formats.
+const (
+	SyslogRFC5424 SyslogFormat = "rfc5424"
+	SyslogRFC3164 SyslogFormat = "rfc3164"
+)
+
+// SyslogFraming selects how messages are delimited on stream transports (RFC 6587).
+// Datagram transports always carry exactly one message per packet.
+type SyslogFraming string
+
+// Supported stream framings.
+const (
+	SyslogOctetCounting  SyslogFraming = "octet-counting"
+	SyslogNonTransparent SyslogFraming = "non-transparent"
+)
+
+// defaultSyslogSeverities returns a fresh level-to-severity map on every call.
+func defaultSyslogSeverities() map[zapcore.Level]SyslogSeverity {
+	return map[zapcore.Level]SyslogSeverity{
+		zapcore.DebugLevel:  SeverityDebug,
+		zapcore.InfoLevel:   SeverityInfo,
+		zapcore.WarnLevel:   SeverityWarning,
+		zapcore.ErrorLevel:  SeverityError,
+		zapcore.DPanicLevel: SeverityCritical,
+		zapcore.PanicLevel:  SeverityAlert,
+		zapcore.FatalLevel:  SeverityEmergency,
+	}
+}
+
+// localSyslogPaths lists the well-known local syslog sockets, probed in order.
+var localSyslogPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// SyslogConfig holds configuration options for a SyslogSink.
+type SyslogConfig struct {
+	// Network is one of "udp", "tcp", "tls", "unix" (stream) or "unixgram", including the
+	// "4"/"6" suffixed variants of udp and tcp. When both Network and Address are empty,
+	// the local syslog daemon socket is used.
+	Network string
+	Address string
+
+	// TLSConfig is used when Network is "tls". A nil value uses the zero tls.Config.
+	TLSConfig *tls.Config
+
+	// Format defaults to SyslogRFC5424.
+	Format SyslogFormat
+
+	// Framing applies to stream transports only and defaults to SyslogOctetCounting.
+	Framing SyslogFraming
+
+	// Facility defaults to FacilityUser. FacilityKern is reserved for the kernel,
+	// so its zero value is treated as unset.
+	Facility SyslogFacility
+
+	// Severities overrides the default level-to-severity mapping per level.
+	Severities map[zapcore.Level]SyslogSeverity
+
+	// Header fields. Hostname defaults to os.Hostname, AppName to the executable name
+	// and ProcID to the process ID. MsgID is left as the nil value ("-") when empty.
+	Hostname string
+	AppName  string
+	ProcID   string
+	MsgID    string
+
+	// StructuredDataID is the SD-ID of the element carrying the entry's fields in RFC 5424
+	// messages. Defaults to "fields@32473" (32473 is the IANA example enterprise number).
+	StructuredDataID string
+
+	// Level filters entries on top of the logger's own level. A nil value accepts every entry.
+	Level zapcore.LevelEnabler
+
+	// EncoderConfig controls how time-typed field values are rendered.
+	// A zero value uses DefaultTextEncoderConfig.
+	EncoderConfig zapcore.EncoderConfig
+
+	DialTimeout  time.Duration // defaults to 5s
+	WriteTimeout time.Duration // zero means no write deadline
+	MinBackoff   time.Duration // first reconnect delay, defaults to 100ms
+	MaxBackoff   time.Duration // reconnect delay cap, defaults to 30s
+}
+
+// applySyslogDefaults fills the zero-valued fields of cfg in place.
+func applySyslogDefaults(cfg *SyslogConfig) {
+	if cfg.Format == "" {
+		cfg.Format = SyslogRFC5424
+	}
+	if cfg.Framing == "" {
+		cfg.Framing = SyslogOctetCounting
+	}
+	if cfg.Facility == FacilityKern {
+		cfg.Facility = FacilityUser
+	}
+	severities := defaultSyslogSeverities()
+	for lvl, sev := range cfg.Severities {
+		severities[lvl] = sev
+	}
+	cfg.Severities = severities
+	if cfg.Hostname == "" {
+		cfg.Hostname, _ = os.Hostname()
+	}
+	if cfg.AppName == "" {
+		cfg.AppName = filepath.Base(os.Args[0])
+	}
+	if cfg.ProcID == "" {
+		cfg.ProcID = strconv.Itoa(os.Getpid())
+	}
+	if cfg.StructuredDataID == "" {
+		cfg.StructuredDataID = "fields@32473"
+	}
+	if cfg.Level == nil {
+		cfg.Level = zapcore.DebugLevel
+	}
+	if cfg.EncoderConfig.EncodeTime == nil {
+		cfg.EncoderConfig = DefaultTextEncoderConfig
+	}
+	if cfg.DialTimeout == 0 {
+		cfg.DialTimeout = defaultDialTimeout
+	}
+}
+
+// SyslogWriter is a zapcore.WriteSyncer that delivers one syslog message per Write call
+// over UDP, TCP, TLS or a Unix socket. Stream transports apply the configured RFC 6587
+// framing. Broken connections are re-dialed with exponential backoff.
+type SyslogWriter struct {
+	conn    *redialConn
+	stream  bool
+	framing SyslogFraming
+}
+
+// NewSyslogWriter dials the transport described by cfg.
+// Only the transport-related fields (Network, Address, TLSConfig, Framing, timeouts
+// and backoff bounds) are used.
+func NewSyslogWriter(cfg SyslogConfig) (*SyslogWriter, error) {
+	applySyslogDefaults(&cfg)
+
+	dial, stream, err := syslogDialer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	w := &SyslogWriter{
+		conn:    newRedialConn(dial, cfg.WriteTimeout, cfg.MinBackoff, cfg.MaxBackoff),
+		stream:  stream,
+		framing: cfg.Framing,
+	}
+
+	// Dial eagerly so that a wrong address is reported at construction
+	w.conn.mu.Lock()
+	err = w.conn.connect()
+	w.conn.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("dslogger: syslog dial: %w", err)
+	}
+	return w, nil
+}
+
+// syslogDialer returns the dial function for cfg and whether the transport is a stream.
+func syslogDialer(cfg SyslogConfig) (func() (net.Conn, error), bool, error) {
+	d := &net.Dialer{Timeout: cfg.DialTimeout}
+
+	switch cfg.Network {
+	case "":
+		if cfg.Address != "" {
+			return nil, false, errors.New("dslogger: syslog address set without a network")
+		}
+		return dialLocalSyslog(d), false, nil
+	case "udp", "udp4", "udp6", "unixgram":
+		return func() (net.Conn, error) { return d.Dial(cfg.Network, cfg.Address) }, false, nil
+	case "tcp", "tcp4", "tcp6", "unix":
+		return func() (net.Conn, error) { return d.Dial(cfg.Network, cfg.Address) }, true, nil
+	case "tls":
+		td := &tls.Dialer{NetDialer: d, Config: cfg.TLSConfig}
+		return func() (net.Conn, error) { return td.Dial("tcp", cfg.Address) }, true, nil
+	default:
+		return nil, false, fmt.Errorf("dslogger: unsupported syslog network %q", cfg.Network)
+	}
+}
+
+// dialLocalSyslog probes the well-known local sockets, datagram first.
+// Local daemons accept one message per write on both socket types,
+// so the local transport is always treated as unframed.
+func dialLocalSyslog(d *net.Dialer) func() (net.Conn, error) {
+	return func() (net.Conn, error) {
+		for _, path := range localSyslogPaths {
+			for _, network := range []string{"unixgram", "unix"} {
+				if conn, err := d.Dial(network, path); err == nil {
+					return conn, nil
+				}
+			}
+		}
+		return nil, errors.New("dslogger: no local syslog socket found")
+	}
+}
+
+// Write sends p as a single syslog message.
+func (w *SyslogWriter) Write(p []byte) (int, error) {
+	if !w.stream {
+		return w.conn.Write(p)
+	}
+
+	buf := _pool.Get()
+	defer buf.Free()
+	switch w.framing {
+	case SyslogNonTransparent:
+		buf.AppendBytes(p)
+		buf.AppendByte('\n')
+	default:
+		buf.AppendInt(int64(len(p)))
+		buf.AppendByte(' ')
+		buf.AppendBytes(p)
+	}
+	if _, err := w.conn.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sync is a no-op, messages are written to the socket as they arrive.
+func (w *SyslogWriter) Sync() error {
+	return nil
+}
+
+// Connected reports whether the writer currently holds an open connection.
+func (w *SyslogWriter) Connected() bool {
+	return w.conn.Connected()
+}
+
+// Close closes the underlying connection.
+func (w *SyslogWriter) Close() error {
+	return w.conn.Close()
+}
+
+// SyslogSink is a zapcore.Core that formats entries as RFC 5424 or RFC 3164 syslog
+// messages and sends them through a SyslogWriter. Attach it with WithSink.
+//
+// In RFC 5424 mode the entry's fields become a structured data element, in RFC 3164
+// mode (which has no structured data) they are appended to the message as key=value.
+type SyslogSink struct {
+	cfg    *SyslogConfig
+	writer *SyslogWriter
+	fields *dsConsoleEncoder // accumulates context fields from With()
+}
+
+// NewSyslogSink creates a SyslogSink and dials its transport.
+func NewSyslogSink(cfg SyslogConfig) (*SyslogSink, error) {
+	applySyslogDefaults(&cfg)
+
+	w, err := NewSyslogWriter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &SyslogSink{
+		cfg:    &cfg,
+		writer: w,
+		fields: newDSConsoleEncoder(&Config{}, cfg.EncoderConfig, ""),
+	}, nil
+}
+
+// Writer returns the underlying transport.
+func (s *SyslogSink) Writer() *SyslogWriter {
+	return s.writer
+}
+
+// Enabled reports whether the sink accepts entries at the given level.
+func (s *SyslogSink) Enabled(lvl zapcore.Level) bool {
+	return s.cfg.Level.Enabled(lvl)
+}
+
+// With returns a copy of the sink carrying the given context fields.
+func (s *SyslogSink) With(fields []zapcore.Field) zapcore.Core {
+	enc := s.fields.Clone().(*dsConsoleEncoder)
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return &SyslogSink{cfg: s.cfg, writer: s.writer, fields: enc}
+}
+
+// Check adds the sink to the checked entry if the level is enabled.
+func (s *SyslogSink) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if s.Enabled(ent.Level) {
+		return ce.AddCore(ent, s)
+	}
+	return ce
+}
+
+// Write formats and sends a single entry.
+func (s *SyslogSink) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	enc := s.fields
+	if len(fields) > 0 {
+		enc = s.fields.Clone().(*dsConsoleEncoder)
+		for _, f := range fields {
+			f.AddTo(enc)
+		}
+	}
+
+	// Stacks are escaped onto one line, as newline-framed transports split on them
+	pairs := enc.pairs
+	if stacks := sinkStackPairs(fields); len(stacks) > 0 {
+		pairs = slices.Clip(pairs)
+		for _, p := range stacks {
+			p.val = sanitizeLogString(p.val)
+			pairs = append(pairs, p)
+		}
+	}
+
+	buf := _pool.Get()
+	defer buf.Free()
+	if s.cfg.Format == SyslogRFC3164 {
+		s.formatRFC3164(buf, ent, pairs)
+	} else {
+		s.formatRFC5424(buf, ent, pairs)
+	}
+	_, err := s.writer.Write(buf.Bytes())
+	return err
+}
+
+// Sync flushes the transport.
+func (s *SyslogSink) Sync() error {
+	return s.writer.Sync()
+}
+
+// Close closes the transport. Logger.Close calls it for sinks attached via WithSink.
+func (s *SyslogSink) Close() error {
+	return s.writer.Close()
+}
+
+// priority computes the PRI value for the given level.
+func (s *SyslogSink) priority(lvl zapcore.Level) int {
+	sev, ok := s.cfg.Severities[lvl]
+	if !ok {
+		sev = SeverityNotice
+	}
+	return int(s.cfg.Facility)*8 + int(sev)
+}
+
+// formatRFC5424 renders:
+//
+//	<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD-ID k="v" ...] MSG
+func (s *SyslogSink) formatRFC5424(buf *buffer.Buffer, ent zapcore.Entry, pairs []kvPair) {
+	buf.AppendByte('<')
+	buf.AppendInt(int64(s.priority(ent.Level)))
+	buf.AppendString(">1 ")
+	buf.AppendString(ent.Time.Format("2006-01-02T15:04:05.000000Z07:00"))
+	buf.AppendByte(' ')
+	buf.AppendString(syslogHeaderField(s.cfg.Hostname, 255))
+	buf.AppendByte(' ')
+	buf.AppendString(syslogHeaderField(s.cfg.AppName, 48))
+	buf.AppendByte(' ')
+	buf.AppendString(syslogHeaderField(s.cfg.ProcID, 128))
+	buf.AppendByte(' ')
+	buf.AppendString(syslogHeaderField(s.cfg.MsgID, 32))
+	buf.AppendByte(' ')
+
+	if len(pairs) == 0 {
+		buf.AppendByte('-')
+	} else {
+		buf.AppendByte('[')
+		buf.AppendString(s.cfg.StructuredDataID)
+		for _, p := range pairs {
+			buf.AppendByte(' ')
+			buf.AppendString(syslogSDName(p.key))
+			buf.AppendString(`="`)
+			buf.AppendString(syslogSDEscaper.Replace(p.val))
+			buf.AppendByte('"')
+		}
+		buf.AppendByte(']')
+	}
+
+	if ent.Message != "" {
+		buf.AppendByte(' ')
+		buf.AppendString(sanitizeLogString(ent.Message))
+	}
+}
+
+// formatRFC3164 renders:
+//
+//	<PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG k=v ...
+func (s *SyslogSink) formatRFC3164(buf *buffer.Buffer, ent zapcore.Entry, pairs []kvPair) {
+	buf.AppendByte('<')
+	buf.AppendInt(int64(s.priority(ent.Level)))
+	buf.AppendByte('>')
+	buf.AppendString(ent.Time.Format(time.Stamp))
+	buf.AppendByte(' ')
+	buf.AppendString(syslogHeaderField(s.cfg.Hostname, 255))
+	buf.AppendByte(' ')
+	buf.AppendString(syslogHeaderField(s.cfg.AppName, 32))
+	buf.AppendByte('[')
+	buf.AppendString(syslogHeaderField(s.cfg.ProcID, 128))
+	buf.AppendString("]: ")
+	buf.AppendString(sanitizeLogString(ent.Message))
+	for _, p := range pairs {
+		buf.AppendByte(' ')
+		buf.AppendString(p.key)
+		buf.AppendByte('=')
+		buf.AppendString(p.val)
+	}
+}
+
+// syslogHeaderField restricts a header value to printable US-ASCII (no spaces),
+// truncates it to maxLen and substitutes the nil value "-" when empty.
+func syslogHeaderField(s string, maxLen int) string {
+	if s == "" {
+		return "-"
+	}
+	var b strings.Builder
+	for i := 0; i < len(s) && b.Len() < maxLen; i++ {
+		c := s[i]
+		if c < 33 || c > 126 {
+			c = '_'
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// syslogSDName makes a field key a valid SD-NAME: printable US-ASCII except
+// '=', ' ', ']' and '"', at most 32 characters.
+func syslogSDName(s string) string {
+	if s == "" {
+		return "_"
+	}
+	var b strings.Builder
+	for i := 0; i < len(s) && b.Len() < 32; i++ {
+		c := s[i]
+		if c < 33 || c > 126 || c == '=' || c == ']' || c == '"' {
+			c = '_'
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// syslogSDEscaper escapes the characters RFC 5424 requires inside PARAM-VALUE.
+var syslogSDEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
@@ -0,0 +1,431 @@
+package dslogger
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// syslogTestServer is an in-process syslog receiver. Stream connections are
+// parsed with RFC 6587 octet-counting or newline framing, datagrams are taken whole.
+type syslogTestServer struct {
+	addr string
+	msgs chan string
+	ln   net.Listener
+	pc   net.PacketConn
+}
+
+// newSyslogTestServer starts a receiver on network ("udp", "tcp", "tls", "unix" or
+// "unixgram") and returns the address a SyslogConfig should dial.
+func newSyslogTestServer(t *testing.T, network string, framing SyslogFraming) (*syslogTestServer, *tls.Config) {
+	t.Helper()
+	s := &syslogTestServer{msgs: make(chan string, 64)}
+	var clientTLS *tls.Config
+
+	var err error
+	switch network {
+	case "udp":
+		s.pc, err = net.ListenPacket("udp", "127.0.0.1:0")
+	case "unixgram":
+		s.pc, err = net.ListenPacket("unixgram", filepath.Join(t.TempDir(), "syslog.sock"))
+	case "tcp":
+		s.ln, err = net.Listen("tcp", "127.0.0.1:0")
+	case "unix":
+		s.ln, err = net.Listen("unix", filepath.Join(t.TempDir(), "syslog.sock"))
+	case "tls":
+		var serverTLS *tls.Config
+		serverTLS, clientTLS = testTLSConfigs(t)
+		s.ln, err = tls.Listen("tcp", "127.0.0.1:0", serverTLS)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.pc != nil {
+		s.addr = s.pc.LocalAddr().String()
+		go s.servePackets()
+		t.Cleanup(func() { _ = s.pc.Close() })
+	} else {
+		s.addr = s.ln.Addr().String()
+		go s.serveStream(framing)
+		t.Cleanup(func() { _ = s.ln.Close() })
+	}
+	return s, clientTLS
+}
+
+func (s *syslogTestServer) servePackets() {
+	buf := make([]byte, 64*1024)
+	for {
+		n, _, err := s.pc.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		s.msgs <- string(buf[:n])
+	}
+}
+
+func (s *syslogTestServer) serveStream(framing SyslogFraming) {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			r := bufio.NewReader(conn)
+			for {
+				msg, err := readSyslogFrame(r, framing)
+				if err != nil {
+					return
+				}
+				s.msgs <- msg
+			}
+		}()
+	}
+}
+
+// readSyslogFrame reads one RFC 6587 frame.
+func readSyslogFrame(r *bufio.Reader, framing SyslogFraming) (string, error) {
+	if framing == SyslogNonTransparent {
+		line, err := r.ReadString('\n')
+		return strings.TrimSuffix(line, "\n"), err
+	}
+	lenStr, err := r.ReadString(' ')
+	if err != nil {
+		return "", err
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(lenStr, " "))
+	if err != nil {
+		return "", err
+	}
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return "", err
+	}
+	return string(msg), nil
+}
+
+// next waits for the next received message.
+func (s *syslogTestServer) next(t *testing.T) string {
+	t.Helper()
+	select {
+	case m := <-s.msgs:
+		return m
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for syslog message")
+		return ""
+	}
+}
+
+// testTLSConfigs returns a server config with a fresh self-signed certificate
+// and a client config that trusts it.
+func testTLSConfigs(t *testing.T) (*tls.Config, *tls.Config) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "dslogger-test"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	server := &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	client := &tls.Config{RootCAs: pool}
+	return server, client
+}
+
+var rfc5424Re = regexp.MustCompile(`^<(\d+)>1 (\S+) (\S+) (\S+) (\S+) (\S+) (-|\[.*\]) ?(.*)$`)
+
+// TestSyslogRFC5424OverUDP checks header layout, PRI and structured data.
+func TestSyslogRFC5424OverUDP(t *testing.T) {
+	srv, _ := newSyslogTestServer(t, "udp", "")
+	sink, err := NewSyslogSink(SyslogConfig{
+		Network:  "udp",
+		Address:  srv.addr,
+		Facility: FacilityLocal0,
+		Hostname: "host1",
+		AppName:  "myapp",
+		ProcID:   "42",
+		MsgID:    "ID7",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := NewConsoleLogger("info", quietConsoleConfig(), WithSink(sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	logger.Warn("disk almost full", "path", `/var/"x"]`, "pct", 93)
+
+	m := rfc5424Re.FindStringSubmatch(srv.next(t))
+	if m == nil {
+		t.Fatal("message does not match RFC 5424 layout")
+	}
+	// local0 (16) * 8 + warning (4)
+	if m[1] != "132" {
+		t.Errorf("PRI = %s, want 132", m[1])
+	}
+	if _, err := time.Parse(time.RFC3339Nano, m[2]); err != nil {
+		t.Errorf("timestamp %q is not RFC 3339: %v", m[2], err)
+	}
+	if m[3] != "host1" || m[4] != "myapp" || m[5] != "42" || m[6] != "ID7" {
+		t.Errorf("header = %v", m[3:7])
+	}
+	wantSD := `[fields@32473 path="/var/\"x\"\]" pct="93"]`
+	if m[7] != wantSD {
+		t.Errorf("SD = %s, want %s", m[7], wantSD)
+	}
+	if m[8] != "disk almost full" {
+		t.Errorf("MSG = %q", m[8])
+	}
+}
+
+// TestSyslogSeverityMapping checks the default and overridden level-to-severity mapping.
+func TestSyslogSeverityMapping(t *testing.T) {
+	srv, _ := newSyslogTestServer(t, "udp", "")
+	sink, err := NewSyslogSink(SyslogConfig{
+		Network:    "udp",
+		Address:    srv.addr,
+		Severities: map[zapcore.Level]SyslogSeverity{zapcore.InfoLevel: SeverityNotice},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := NewConsoleLogger("debug", quietConsoleConfig(), WithSink(sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	// user (1) * 8 + severity
+	tests := []struct {
+		log  func(string, ...any)
+		want string
+	}{
+		{logger.Debug, "<15>"},
+		{logger.Info, "<13>"},
+		{logger.Warn, "<12>"},
+		{logger.Error, "<11>"},
+	}
+	for _, tt := range tests {
+		tt.log("x")
+		if got := srv.next(t); !strings.HasPrefix(got, tt.want) {
+			t.Errorf("got %q, want prefix %s", got, tt.want)
+		}
+	}
+}
+
+// TestSyslogRFC3164 checks the BSD layout with fields appended to the message.
+func TestSyslogRFC3164(t *testing.T) {
+	srv, _ := newSyslogTestServer(t, "unixgram", "")
+	sink, err := NewSyslogSink(SyslogConfig{
+		Network:  "unixgram",
+		Address:  srv.addr,
+		Format:   SyslogRFC3164,
+		Facility: FacilityDaemon,
+		Hostname: "h",
+		AppName:  "app",
+		ProcID:   "7",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := NewConsoleLogger("info", quietConsoleConfig(), WithSink(sink), WithCustomFields("env", "prod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	logger.Error("failed", "code", 3)
+
+	re := regexp.MustCompile(`^<27>[A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2} h app\[7\]: failed env=prod code=3$`)
+	if got := srv.next(t); !re.MatchString(got) {
+		t.Errorf("RFC 3164 mismatch: %q", got)
+	}
+}
+
+// TestSyslogStack checks that captured stacks reach the sink, escaped onto the line.
+func TestSyslogStack(t *testing.T) {
+	srv, _ := newSyslogTestServer(t, "unixgram", "")
+	sink, err := NewSyslogSink(SyslogConfig{Network: "unixgram", Address: srv.addr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := quietConsoleConfig()
+	cfg.StacktraceLevel = "error"
+	logger, err := NewConsoleLogger("info", cfg, WithSink(sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	logger.Error("failed")
+
+	got := srv.next(t)
+	// The SD-PARAM escaping doubles the backslash of the escaped newline
+	want := sinkStacktraceKey + `="` + dsloggerPkg + ".TestSyslogStack\\\\n\t"
+	if !strings.Contains(got, want) || strings.Contains(got, "\n") {
+		t.Errorf("stack missing from %q", got)
+	}
+}
+
+// TestSyslogStreamTransports sends through every stream transport and framing.
+func TestSyslogStreamTransports(t *testing.T) {
+	tests := []struct {
+		network string
+		framing SyslogFraming
+	}{
+		{"tcp", SyslogOctetCounting},
+		{"tcp", SyslogNonTransparent},
+		{"tls", SyslogOctetCounting},
+		{"unix", SyslogOctetCounting},
+	}
+	for _, tt := range tests {
+		t.Run(tt.network+"/"+string(tt.framing), func(t *testing.T) {
+			srv, clientTLS := newSyslogTestServer(t, tt.network, tt.framing)
+			sink, err := NewSyslogSink(SyslogConfig{
+				Network:   tt.network,
+				Address:   srv.addr,
+				TLSConfig: clientTLS,
+				Framing:   tt.framing,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger, err := NewConsoleLogger("info", quietConsoleConfig(), WithSink(sink))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer logger.Close()
+
+			logger.Info("first")
+			logger.WithService("auth").Info("second", "user", "james")
+
+			if got := srv.next(t); !strings.HasSuffix(got, " - first") {
+				t.Errorf("first message = %q", got)
+			}
+			if got := srv.next(t); !strings.HasSuffix(got, `[fields@32473 service="auth" user="james"] second`) {
+				t.Errorf("second message = %q", got)
+			}
+		})
+	}
+}
+
+// TestSyslogReconnect verifies that a dropped TCP connection is re-established.
+func TestSyslogReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	conns := make(chan net.Conn, 4)
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conns <- c
+		}
+	}()
+
+	w, err := NewSyslogWriter(SyslogConfig{Network: "tcp", Address: ln.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// Server drops the first connection
+	first := <-conns
+	_ = first.Close()
+
+	// Writes eventually fail on the dead connection and reconnect transparently
+	deadline := time.Now().Add(2 * time.Second)
+	var second net.Conn
+	for second == nil && time.Now().Before(deadline) {
+		_, _ = w.Write([]byte("ping"))
+		select {
+		case second = <-conns:
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+	if second == nil {
+		t.Fatal("writer did not reconnect")
+	}
+	defer second.Close()
+
+	if _, err := w.Write([]byte("after")); err != nil {
+		t.Fatalf("write after reconnect: %v", err)
+	}
+	r := bufio.NewReader(second)
+	for {
+		msg, err := readSyslogFrame(r, SyslogOctetCounting)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg == "after" {
+			break
+		}
+	}
+}
+
+// TestSyslogBackoff verifies that a dead peer is not re-dialed on every write.
+func TestSyslogBackoff(t *testing.T) {
+	dials := 0
+	c := newRedialConn(func() (net.Conn, error) {
+		dials++
+		return nil, net.ErrClosed
+	}, 0, time.Hour, time.Hour)
+
+	for i := 0; i < 5; i++ {
+		_, _ = c.Write([]byte("x"))
+	}
+	if dials != 1 {
+		t.Errorf("dials = %d, want 1 within the backoff window", dials)
+	}
+	if _, err := c.Write([]byte("x")); err != errReconnectBackoff {
+		t.Errorf("err = %v, want errReconnectBackoff", err)
+	}
+}
+
+// quietConsoleConfig returns a default config whose console output is discarded,
+// for tests that only inspect a sink.
+func quietConsoleConfig() *Config {
+	cfg := NewDefaultConfig()
+	cfg.ConsoleWriter = io.Discard
+	cfg.NoColor = true
+	return &cfg
+}
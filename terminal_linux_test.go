@@ -0,0 +1,135 @@
+//go:build linux
+
+package dslogger
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// openPTY opens a pseudo-terminal pair, skipping the test when the system has none.
+func openPTY(t *testing.T) (master, slave *os.File) {
+	t.Helper()
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("no pseudo-terminal support: %v", err)
+	}
+	t.Cleanup(func() { master.Close() })
+
+	fd := int(master.Fd())
+	if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+		t.Skipf("unlockpt: %v", err)
+	}
+	n, err := unix.IoctlGetInt(fd, unix.TIOCGPTN)
+	if err != nil {
+		t.Skipf("ptsname: %v", err)
+	}
+	slave, err = os.OpenFile("/dev/pts/"+strconv.Itoa(n), os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("open pts: %v", err)
+	}
+	t.Cleanup(func() { slave.Close() })
+	return master, slave
+}
+
+// clearColorEnv removes every environment variable that overrides color detection.
+func clearColorEnv(t *testing.T) {
+	t.Helper()
+	for _, k := range []string{"NO_COLOR", "FORCE_COLOR", "CLICOLOR_FORCE", "CLICOLOR"} {
+		t.Setenv(k, "")
+	}
+	t.Setenv("TERM", "xterm-256color")
+}
+
+// fdOnlyWriter wraps a file and only exposes Write and Fd.
+type fdOnlyWriter struct{ f *os.File }
+
+func (w fdOnlyWriter) Write(p []byte) (int, error) { return w.f.Write(p) }
+func (w fdOnlyWriter) Fd() uintptr                 { return w.f.Fd() }
+
+// TestColorDetectionFollowsConsoleWriter checks color detection against the configured
+// writer for every writer/terminal combination.
+func TestColorDetectionFollowsConsoleWriter(t *testing.T) {
+	_, tty := openPTY(t)
+	regular, err := os.Create(filepath.Join(t.TempDir(), "out.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer regular.Close()
+	_, pipe, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pipe.Close()
+
+	tests := []struct {
+		name      string
+		stdout    *os.File
+		writer    io.Writer
+		term      string
+		wantColor bool
+	}{
+		{"stdout tty", tty, nil, "", true},
+		{"stdout pipe", pipe, nil, "", false},
+		{"stdout pipe, writer tty", pipe, tty, "", true},
+		{"stdout tty, writer buffer", tty, &bytes.Buffer{}, "", false},
+		{"stdout tty, writer regular file", tty, regular, "", false},
+		{"writer with Fd on tty", pipe, fdOnlyWriter{tty}, "", true},
+		{"writer tty, TERM=dumb", pipe, tty, "dumb", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearColorEnv(t)
+			if tt.term != "" {
+				t.Setenv("TERM", tt.term)
+			}
+			orig := os.Stdout
+			os.Stdout = tt.stdout
+			defer func() { os.Stdout = orig }()
+
+			cfg := NewDefaultConfig()
+			cfg.ConsoleWriter = tt.writer
+			applyDefaults(&cfg)
+			if got := !cfg.NoColor; got != tt.wantColor {
+				t.Errorf("color = %v, want %v", got, tt.wantColor)
+			}
+		})
+	}
+}
+
+// TestColorOnPseudoTerminal logs through a pty and reads the colored line back.
+func TestColorOnPseudoTerminal(t *testing.T) {
+	clearColorEnv(t)
+	master, tty := openPTY(t)
+
+	cfg := NewDefaultConfig()
+	cfg.ConsoleWriter = tty
+	logger, err := NewConsoleLogger("info", &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("on a terminal")
+
+	got := make(chan string, 1)
+	go func() {
+		buf := make([]byte, 4096)
+		n, _ := master.Read(buf)
+		got <- string(buf[:n])
+	}()
+	select {
+	case out := <-got:
+		if !strings.Contains(out, "\033[") || !strings.Contains(out, "on a terminal") {
+			t.Errorf("expected colored output on the terminal, got %q", out)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no output read from the pseudo-terminal")
+	}
+}
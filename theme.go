@@ -0,0 +1,185 @@
+package dslogger
+
+import (
+	"maps"
+	"os"
+	"strconv"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// Theme holds the ANSI color escape sequences applied to each element of console output.
+// Every field is optional, an empty string leaves that element uncolored. Level strings
+// keep their color from Config.LevelFormats.
+//
+// Colors can be any SGR sequence, Color256 and TrueColor build the extended forms.
+type Theme struct {
+	Timestamp string
+	Caller    string
+	Service   string // service name together with its ServiceNameDecorators
+	Key       string // field keys
+
+	// Field values, by type.
+	String string
+	Number string
+	Bool   string
+	Time   string // time and duration values
+	Other  string // reflected values and anything else
+
+	// Messages colors the message per level.
+	Messages map[zapcore.Level]string
+
+	// Stack trace function names and file:line locations.
+	StackFunction string
+	StackLocation string
+}
+
+// clone returns a deep copy of t.
+func (t *Theme) clone() *Theme {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	c.Messages = maps.Clone(t.Messages)
+	return &c
+}
+
+// valueColor returns the color for a value of the given kind.
+func (t *Theme) valueColor(kind valueKind) string {
+	switch kind {
+	case kindString:
+		return t.String
+	case kindNumber:
+		return t.Number
+	case kindBool:
+		return t.Bool
+	case kindTime, kindDuration:
+		return t.Time
+	default:
+		return t.Other
+	}
+}
+
+// Color256 returns the escape sequence selecting color n of the 256-color palette.
+func Color256(n uint8) string {
+	return "\033[38;5;" + strconv.Itoa(int(n)) + "m"
+}
+
+// TrueColor returns the escape sequence selecting a 24-bit RGB foreground color.
+func TrueColor(r, g, b uint8) string {
+	return "\033[38;2;" + strconv.Itoa(int(r)) + ";" + strconv.Itoa(int(g)) + ";" + strconv.Itoa(int(b)) + "m"
+}
+
+// DarkTheme returns a theme for dark terminal backgrounds.
+// It is also the default palette of LogFormatPretty when Config.Theme is nil.
+func DarkTheme() *Theme {
+	return &Theme{
+		Timestamp: ansiDim,
+		Caller:    ansiDim,
+		Service:   ansiBold,
+		Key:       ansiBlue,
+		String:    ansiGreen,
+		Number:    ansiMagenta,
+		Bool:      ansiYellow,
+		Time:      ansiCyan,
+		Messages: map[zapcore.Level]string{
+			zapcore.WarnLevel:  ansiYellow,
+			zapcore.ErrorLevel: ansiRed,
+		},
+		StackFunction: ansiBold + ansiRed,
+		StackLocation: ansiDim,
+	}
+}
+
+// LightTheme returns a theme for light terminal backgrounds, avoiding yellow and
+// cyan which are hard to read on white.
+func LightTheme() *Theme {
+	return &Theme{
+		Timestamp: Color256(244),
+		Caller:    Color256(244),
+		Service:   ansiBold,
+		Key:       Color256(25),
+		String:    Color256(28),
+		Number:    Color256(90),
+		Bool:      Color256(130),
+		Time:      Color256(31),
+		Messages: map[zapcore.Level]string{
+			zapcore.WarnLevel:  Color256(130),
+			zapcore.ErrorLevel: Color256(160),
+		},
+		StackFunction: ansiBold + Color256(160),
+		StackLocation: Color256(244),
+	}
+}
+
+// HighContrastTheme returns a theme using bold, bright colors only.
+func HighContrastTheme() *Theme {
+	return &Theme{
+		Timestamp: "\033[97m",
+		Caller:    "\033[97m",
+		Service:   ansiBold + "\033[97m",
+		Key:       ansiBold + "\033[96m",
+		String:    "\033[92m",
+		Number:    "\033[95m",
+		Bool:      "\033[93m",
+		Time:      "\033[96m",
+		Other:     "\033[97m",
+		Messages: map[zapcore.Level]string{
+			zapcore.DebugLevel: "\033[97m",
+			zapcore.InfoLevel:  ansiBold + "\033[97m",
+			zapcore.WarnLevel:  ansiBold + "\033[93m",
+			zapcore.ErrorLevel: ansiBold + "\033[91m",
+		},
+		StackFunction: ansiBold + "\033[91m",
+		StackLocation: "\033[97m",
+	}
+}
+
+// plainTheme leaves every element uncolored. Encoders use it when color is disabled.
+var plainTheme = &Theme{}
+
+// consoleTheme returns the theme for console output: Config.Theme, fallback when no
+// theme is configured, or plainTheme when color is disabled.
+func (c *Config) consoleTheme(fallback *Theme) *Theme {
+	switch {
+	case c.NoColor:
+		return plainTheme
+	case c.Theme != nil:
+		return c.Theme
+	case fallback != nil:
+		return fallback
+	default:
+		return plainTheme
+	}
+}
+
+// paint writes text wrapped in color. An empty color writes text as is.
+func paint(buf *buffer.Buffer, color, text string) {
+	if color == "" {
+		buf.AppendString(text)
+		return
+	}
+	buf.AppendString(color)
+	buf.AppendString(text)
+	buf.AppendString(ansiReset)
+}
+
+// colorDisabledByEnv applies the NO_COLOR, FORCE_COLOR, CLICOLOR_FORCE and CLICOLOR
+// conventions. It returns (disabled, true) when the environment decides, and
+// (false, false) when detection should fall through to the terminal check.
+func colorDisabledByEnv() (bool, bool) {
+	if os.Getenv("NO_COLOR") != "" {
+		return true, true
+	}
+	if v := os.Getenv("FORCE_COLOR"); v != "" {
+		return v == "0" || v == "false", true
+	}
+	if v := os.Getenv("CLICOLOR_FORCE"); v != "" && v != "0" {
+		return false, true
+	}
+	if os.Getenv("CLICOLOR") == "0" {
+		return true, true
+	}
+	return false, false
+}
@@ -0,0 +1,141 @@
+package dslogger
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// testTheme uses a distinct marker per element so the assertions can tell them apart.
+func testTheme() *Theme {
+	return &Theme{
+		Timestamp: "<ts>",
+		Caller:    "<caller>",
+		Service:   "<svc>",
+		Key:       "<key>",
+		String:    "<str>",
+		Number:    "<num>",
+		Bool:      "<bool>",
+		Time:      "<time>",
+		Messages:  map[zapcore.Level]string{zapcore.WarnLevel: "<warn>"},
+	}
+}
+
+// TestThemeColorsTextConsole checks every themed element of the text console format,
+// and that the file output of the same logger stays uncolored.
+func TestThemeColorsTextConsole(t *testing.T) {
+	withTempLogFile(t, func(path string, cfg *Config) {
+		var buf bytes.Buffer
+		cfg.ConsoleWriter = &buf
+		cfg.ForceColor = true
+		cfg.Theme = testTheme()
+		cfg.LogFileFormat = LogFormatText
+		fixed := func(t2 time.Time, enc zapcore.PrimitiveArrayEncoder) { enc.AppendString("FIXED_TIME") }
+		cfg.ConsoleConfig.EncodeTime = fixed
+		cfg.FileConfig.EncodeTime = fixed
+
+		logger, err := NewLogger("info", cfg, WithServiceName("api"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger.WithFields("region", "eu").Warn("slow", "ms", 250, "cached", false, "took", time.Second)
+		_ = logger.Close()
+
+		out := buf.String()
+		for _, want := range []string{
+			"<ts>FIXED_TIME" + ansiReset,
+			"<svc>[api]" + ansiReset + " <warn>slow" + ansiReset,
+			"<key>region" + ansiReset + ": <str>eu" + ansiReset,
+			"<key>ms" + ansiReset + ": <num>250" + ansiReset,
+			"<key>cached" + ansiReset + ": <bool>false" + ansiReset,
+			"<key>took" + ansiReset + ": <time>1s" + ansiReset,
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("console output missing %q:\n%q", want, out)
+			}
+		}
+
+		data, _ := os.ReadFile(path)
+		want := "FIXED_TIME | WARN  | [api] slow | region: eu | ms: 250 | cached: false | took: 1s\n"
+		if string(data) != want {
+			t.Errorf("file output is themed:\ngot:  %q\nwant: %q", data, want)
+		}
+	})
+}
+
+// TestThemeIgnoredWhenColorDisabled verifies NoColor wins over a configured theme.
+func TestThemeIgnoredWhenColorDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := NewDefaultConfig()
+	cfg.ConsoleWriter = &buf
+	cfg.NoColor = true
+	cfg.Theme = testTheme()
+	logger, err := NewConsoleLogger("info", &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("plain", "k", "v")
+	_ = logger.Sync()
+	if strings.Contains(buf.String(), "<") || strings.Contains(buf.String(), "\033[") {
+		t.Errorf("colored output with NoColor: %q", buf.String())
+	}
+}
+
+// TestColorEnvironment checks NO_COLOR, FORCE_COLOR, CLICOLOR_FORCE and CLICOLOR.
+// The console writer is a buffer, so without an environment override color is off.
+func TestColorEnvironment(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		forceColor  bool
+		wantNoColor bool
+	}{
+		{"none", nil, false, true},
+		{"FORCE_COLOR", map[string]string{"FORCE_COLOR": "1"}, false, false},
+		{"FORCE_COLOR=0", map[string]string{"FORCE_COLOR": "0"}, false, true},
+		{"CLICOLOR_FORCE", map[string]string{"CLICOLOR_FORCE": "1"}, false, false},
+		{"CLICOLOR=0", map[string]string{"CLICOLOR": "0", "CLICOLOR_FORCE": "0"}, false, true},
+		{"NO_COLOR beats FORCE_COLOR", map[string]string{"NO_COLOR": "1", "FORCE_COLOR": "1"}, false, true},
+		{"ForceColor beats NO_COLOR", map[string]string{"NO_COLOR": "1"}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, k := range []string{"NO_COLOR", "FORCE_COLOR", "CLICOLOR_FORCE", "CLICOLOR"} {
+				t.Setenv(k, tt.env[k])
+			}
+			cfg := NewDefaultConfig()
+			cfg.ConsoleWriter = &bytes.Buffer{}
+			cfg.ForceColor = tt.forceColor
+			applyDefaults(&cfg)
+			if cfg.NoColor != tt.wantNoColor {
+				t.Errorf("NoColor = %v, want %v", cfg.NoColor, tt.wantNoColor)
+			}
+		})
+	}
+}
+
+// TestThemeColorHelpers checks the 256-color and truecolor sequences.
+func TestThemeColorHelpers(t *testing.T) {
+	if got := Color256(208); got != "\033[38;5;208m" {
+		t.Errorf("Color256 = %q", got)
+	}
+	if got := TrueColor(255, 128, 0); got != "\033[38;2;255;128;0m" {
+		t.Errorf("TrueColor = %q", got)
+	}
+}
+
+// TestCloneConfigCopiesTheme verifies the theme is deep-copied with the config.
+func TestCloneConfigCopiesTheme(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.Theme = DarkTheme()
+	cloned := cloneConfig(&cfg)
+	cfg.Theme.Key = "changed"
+	cfg.Theme.Messages[zapcore.InfoLevel] = "changed"
+	if cloned.Theme.Key == "changed" || cloned.Theme.Messages[zapcore.InfoLevel] == "changed" {
+		t.Error("cloneConfig shares the theme with the input")
+	}
+}